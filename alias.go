@@ -0,0 +1,95 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+const aliName = "aliases"
+
+// DeprecatedError reports a value supplied by a deprecated alias instead of
+// the variable's current name.
+type DeprecatedError struct {
+	Alias string
+	Name  string
+}
+
+func (e *DeprecatedError) Error() string {
+	return fmt.Sprintf("deprecated variable %s is set, use %s instead", e.Alias, e.Name)
+}
+
+// AliasConflictError reports a variable and one of its deprecated aliases
+// set to different values.
+type AliasConflictError struct {
+	Alias string
+	Name  string
+}
+
+func (e *AliasConflictError) Error() string {
+	return fmt.Sprintf("%s and deprecated %s are set to different values", e.Name, e.Alias)
+}
+
+// aliases parses an aliases tag. Like the env tag, each alias is read under
+// the prefix of its parent struct, unless it starts with "/": aliases:"/PGHOST"
+// names the variable PGHOST wherever the field is nested.
+func aliases(prefix, tag string) []string {
+	if tag == "" {
+		return nil
+	}
+
+	var names []string
+	for _, a := range strings.Split(tag, delimiterVal) {
+		a = strings.TrimSpace(a)
+		if name, ok := strings.CutPrefix(a, "/"); ok {
+			names = append(names, name)
+		} else if a != "" {
+			names = append(names, getTag(prefix, a))
+		}
+	}
+
+	return names
+}
+
+// alias returns the variable a field is read from: its own name, or else
+// the first of its aliases that is set. Conflicting values are reported
+// as warnings, or errors in strict mode, and the field's own name wins.
+func (d *decoder) alias(f *fieldPlan) (string, error) {
+	if len(f.aliases) == 0 {
+		return f.tag, nil
+	}
+
+	if !d.present(f.tag) {
+		for _, a := range f.aliases {
+			if d.present(a) {
+				if d.warn != nil {
+					d.warn(&DeprecatedError{Alias: a, Name: f.tag})
+				}
+				return a, nil
+			}
+		}
+
+		return f.tag, nil
+	}
+
+	val, err := d.resolve(f.tag, nil)
+	if err != nil {
+		return "", err
+	}
+
+	for _, a := range f.aliases {
+		if !d.present(a) {
+			continue
+		}
+
+		aVal, err := d.resolve(a, nil)
+		if err != nil {
+			return "", err
+		}
+
+		if aVal != val {
+			d.warning(&AliasConflictError{Alias: a, Name: f.tag})
+		}
+	}
+
+	return f.tag, nil
+}
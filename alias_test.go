@@ -0,0 +1,112 @@
+package config
+
+import (
+	"errors"
+	"testing"
+)
+
+type ConfigAlias struct {
+	Host string `env:"DATABASE_HOST" aliases:"DB_HOST,PGHOST" default:"localhost"`
+	DB   struct {
+		Port int `env:"PORT" aliases:"PG_PORT"`
+	} `env:"DB"`
+}
+
+func TestAliases(t *testing.T) {
+	var warnings []error
+	warn := OnWarning(func(err error) {
+		warnings = append(warnings, err)
+	})
+
+	c := &ConfigAlias{}
+	if err := ReadENV(c, WithSources(MapSource{"PGHOST": "pg", "DB_PG_PORT": "6432"}), warn); err != nil {
+		t.Error(err)
+	}
+
+	if c.Host != "pg" || c.DB.Port != 6432 {
+		t.Error("alias value", c)
+	}
+
+	var de *DeprecatedError
+	if len(warnings) != 2 || !errors.As(warnings[0], &de) || de.Alias != "PGHOST" || de.Name != "DATABASE_HOST" {
+		t.Error("alias warning", warnings)
+	}
+
+	warnings = nil
+	if err := ReadENV(c, WithSources(MapSource{"DB_HOST": "first", "PGHOST": "second"}), warn); err != nil {
+		t.Error(err)
+	}
+
+	if c.Host != "first" || len(warnings) != 1 || warnings[0].Error() != "deprecated variable DB_HOST is set, use DATABASE_HOST instead" {
+		t.Error("alias order", c.Host, warnings)
+	}
+
+	warnings = nil
+	if err := ReadENV(c, WithSources(MapSource{}), warn); err != nil {
+		t.Error(err)
+	}
+
+	if c.Host != "localhost" || len(warnings) != 0 {
+		t.Error("alias default", c.Host, warnings)
+	}
+}
+
+func TestAliasConflict(t *testing.T) {
+	src := MapSource{"DATABASE_HOST": "new", "DB_HOST": "old", "PGHOST": "new"}
+
+	var warnings []error
+	c := &ConfigAlias{}
+	if err := ReadENV(c, WithSources(src), OnWarning(func(err error) {
+		warnings = append(warnings, err)
+	})); err != nil {
+		t.Error(err)
+	}
+
+	if c.Host != "new" || len(warnings) != 1 || warnings[0].Error() != "DATABASE_HOST and deprecated DB_HOST are set to different values" {
+		t.Error("alias conflict warning", c.Host, warnings)
+	}
+
+	err := ReadENV(c, WithSources(src), Strict())
+
+	var ce *AliasConflictError
+	if !errors.As(err, &ce) || ce.Alias != "DB_HOST" {
+		t.Error("alias conflict strict", err)
+	}
+
+	if err := ReadENV(c, WithSources(MapSource{"DATABASE_HOST": "same", "DB_HOST": "same"}), Strict()); err != nil {
+		t.Error("alias same value", err)
+	}
+}
+
+func TestAliasKnown(t *testing.T) {
+	src := MapSource{"DB_HOST": "old", "DB_PG_PORT_FILE": "/dev/null"}
+
+	if err := ReadENV(&ConfigAlias{}, WithSources(src), CheckUnknown("DB"), Strict()); err != nil {
+		t.Error("aliases unknown", err)
+	}
+}
+
+func TestAliasAbsolute(t *testing.T) {
+	type config struct {
+		DB struct {
+			Port int `env:"PORT" aliases:"PG_PORT,/PGPORT"`
+		} `env:"DB"`
+	}
+
+	c := &config{}
+	if err := ReadENV(c, WithSources(MapSource{"PGPORT": "6432", "PG_PORT": "1"})); err != nil {
+		t.Error(err)
+	}
+
+	if c.DB.Port != 6432 {
+		t.Error("alias absolute PGPORT", c.DB.Port)
+	}
+
+	if err := ReadENV(c, WithSources(MapSource{"DB_PG_PORT": "5433", "PGPORT": "6432"})); err != nil {
+		t.Error(err)
+	}
+
+	if c.DB.Port != 5433 {
+		t.Error("alias prefixed DB_PG_PORT", c.DB.Port)
+	}
+}
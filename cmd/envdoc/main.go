@@ -0,0 +1,76 @@
+// Command envdoc lists the variables a config struct reads, without running
+// the program that uses it.
+//
+// Usage:
+//
+//	envdoc [-format markdown|json|env] <package> <type>
+//
+// The package is a directory or an import path. Nested and embedded structs
+// are followed across files and packages, and names are resolved as
+// config.ReadENV resolves them.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/GMTror/config/internal/gosrc"
+	"github.com/GMTror/config/internal/render"
+)
+
+func main() {
+	format := flag.String("format", "markdown", "output format: markdown, json or env")
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "usage: envdoc [-format markdown|json|env] <package> <type>")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() != 2 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if err := document(os.Stdout, flag.Arg(0), flag.Arg(1), *format); err != nil {
+		fmt.Fprintln(os.Stderr, "envdoc:", err)
+		os.Exit(1)
+	}
+}
+
+func document(w io.Writer, path, typ, format string) error {
+	pkg, err := gosrc.Import(path)
+	if err != nil {
+		return err
+	}
+
+	fs, err := pkg.Fields(typ)
+	if err != nil {
+		return err
+	}
+
+	var entries []render.Entry
+	for _, f := range gosrc.Leaves(fs) {
+		entries = append(entries, render.Entry{
+			Name:        f.Var,
+			Field:       f.Path,
+			Type:        f.Pkg.TypeString(f.Type),
+			Default:     f.Tag.Get("default"),
+			Required:    f.Tag.Get("required") == "true",
+			Description: f.Tag.Get("description"),
+			Secret:      f.Secret(),
+		})
+	}
+
+	switch format {
+	case "markdown":
+		return render.Markdown(w, entries)
+	case "json":
+		return render.JSON(w, entries)
+	case "env":
+		return render.DotEnv(w, entries)
+	}
+
+	return fmt.Errorf("unsupported format %q", format)
+}
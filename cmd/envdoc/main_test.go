@@ -0,0 +1,55 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/GMTror/config"
+	"github.com/GMTror/config/internal/envgentest"
+)
+
+func TestDocumentMatchesUsage(t *testing.T) {
+	for format, usage := range map[string]config.Format{
+		"markdown": config.FormatMarkdown,
+		"json":     config.FormatJSON,
+	} {
+		var got, want bytes.Buffer
+		if err := document(&got, "../../internal/envgentest", "Config", format); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := config.Usage(&envgentest.Config{}, &want, usage); err != nil {
+			t.Fatal(err)
+		}
+
+		if got.String() != want.String() {
+			t.Errorf("%s differs from Usage:\n%s\nwant:\n%s", format, got.String(), want.String())
+		}
+	}
+}
+
+func TestDocumentDotEnv(t *testing.T) {
+	var got, want bytes.Buffer
+	if err := document(&got, "github.com/GMTror/config/internal/envgentest", "Config", "env"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := config.WriteDotEnvTemplate(&envgentest.Config{}, &want); err != nil {
+		t.Fatal(err)
+	}
+
+	if got.String() != want.String() {
+		t.Errorf("env differs from WriteDotEnvTemplate:\n%s\nwant:\n%s", got.String(), want.String())
+	}
+}
+
+func TestDocumentErrors(t *testing.T) {
+	var b bytes.Buffer
+	if err := document(&b, "../../internal/envgentest", "Missing", "markdown"); err == nil {
+		t.Error("missing type")
+	}
+
+	if err := document(&b, "../../internal/envgentest", "Config", "yaml"); err == nil {
+		t.Error("unsupported format")
+	}
+}
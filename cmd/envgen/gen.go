@@ -0,0 +1,421 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/printer"
+	"sort"
+	"strings"
+
+	"github.com/GMTror/config/internal/gosrc"
+)
+
+const configPath = "github.com/GMTror/config"
+
+// parsers maps basic types to the strconv call ReadENV decodes them with.
+var parsers = map[string]string{
+	"int":     "strconv.Atoi(v)",
+	"int8":    "strconv.ParseInt(v, 10, 8)",
+	"int16":   "strconv.ParseInt(v, 10, 16)",
+	"int32":   "strconv.ParseInt(v, 10, 32)",
+	"rune":    "strconv.ParseInt(v, 10, 32)",
+	"int64":   "strconv.ParseInt(v, 10, 64)",
+	"uint":    "strconv.ParseUint(v, 10, 32)",
+	"uint8":   "strconv.ParseUint(v, 10, 8)",
+	"byte":    "strconv.ParseUint(v, 10, 8)",
+	"uint16":  "strconv.ParseUint(v, 10, 16)",
+	"uint32":  "strconv.ParseUint(v, 10, 32)",
+	"uint64":  "strconv.ParseUint(v, 10, 64)",
+	"float32": "strconv.ParseFloat(v, 32)",
+	"float64": "strconv.ParseFloat(v, 64)",
+	"bool":    "strconv.ParseBool(v)",
+	"string":  "",
+}
+
+var unsupported = []string{"aliases", "optional", "nonzero", "min", "max", "len", "regexp", "format"}
+
+type generator struct {
+	pkg     *gosrc.Package
+	buf     bytes.Buffer
+	imports map[string]bool
+}
+
+func generate(dir string, names []string) ([]byte, error) {
+	pkg, err := gosrc.Load(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	g := &generator{pkg: pkg, imports: map[string]bool{configPath: true}}
+	for _, name := range names {
+		if err := g.decoder(name); err != nil {
+			return nil, err
+		}
+	}
+
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "// Code generated by \"envgen -type %s\"; DO NOT EDIT.\n\n", strings.Join(names, ","))
+	fmt.Fprintf(&out, "package %s\n\nimport (\n", pkg.Name)
+
+	var std, other []string
+	for path := range g.imports {
+		if strings.Contains(path, ".") {
+			other = append(other, path)
+		} else {
+			std = append(std, path)
+		}
+	}
+	sort.Strings(std)
+	sort.Strings(other)
+	for _, path := range std {
+		fmt.Fprintf(&out, "%q\n", path)
+	}
+	out.WriteString("\n")
+	for _, path := range other {
+		fmt.Fprintf(&out, "%q\n", path)
+	}
+	out.WriteString(")\n")
+	out.Write(g.buf.Bytes())
+
+	src, err := format.Source(out.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("formatting output: %v", err)
+	}
+
+	return src, nil
+}
+
+func (g *generator) printf(format string, args ...interface{}) {
+	fmt.Fprintf(&g.buf, format, args...)
+}
+
+func (g *generator) decoder(name string) error {
+	fs, err := g.pkg.Fields(name)
+	if err != nil {
+		return err
+	}
+
+	g.printf("\n// DecodeENV decodes the environment into c without reflection.\n")
+	g.printf("func (c *%s) DecodeENV(src config.Source) error {\n", name)
+	g.printf("var errs config.Errors\n")
+	if err := g.fields("c", name, "", fs, false); err != nil {
+		return err
+	}
+	g.printf("if len(errs) > 0 {\nreturn errs\n}\nreturn nil\n}\n")
+
+	return nil
+}
+
+// fields writes the decoding of a struct reached through recv, with the
+// same SetDefaults, Validate and presets handling as decodeStruct.
+func (g *generator) fields(recv, typeName, path string, fs []*gosrc.Field, presets bool) error {
+	if typeName != "" && g.pkg.HasMethod(typeName, "SetDefaults") {
+		g.printf("%s.SetDefaults()\n", recv)
+		presets = true
+	}
+
+	for _, f := range fs {
+		for _, name := range unsupported {
+			if _, ok := f.Tag.Lookup(name); ok {
+				return fmt.Errorf("%s: %s tag is not supported", f.Path, name)
+			}
+		}
+
+		if f.Pkg != g.pkg {
+			return fmt.Errorf("%s: fields declared in other packages are not supported", f.Path)
+		}
+
+		if f.Recursive {
+			return fmt.Errorf("%s: recursive types are not supported", f.Path)
+		}
+
+		target := recv + "." + f.Name
+		if f.Leaf() {
+			if err := g.leaf(target, f, presets); err != nil {
+				return err
+			}
+			continue
+		}
+
+		t := f.Type
+		if star, ok := t.(*ast.StarExpr); ok {
+			t = star.X
+			g.printf("if %s == nil {\n%s = new(%s)\n}\n", target, target, g.typeString(t))
+		}
+
+		name := ""
+		if id, ok := t.(*ast.Ident); ok {
+			name = id.Name
+		}
+		if err := g.fields(target, name, f.Path, f.Children, presets); err != nil {
+			return err
+		}
+	}
+
+	if typeName != "" && g.pkg.HasMethod(typeName, "Validate") {
+		g.printf("if err := %s.Validate(); err != nil {\n", recv)
+		g.printf("errs = append(errs, &config.DecodeError{Field: %q, Err: err})\n}\n", path)
+	}
+
+	return nil
+}
+
+func (g *generator) leaf(target string, f *gosrc.Field, presets bool) error {
+	fail := func(err string) string {
+		if f.Secret() {
+			err = "config.Redact(" + err + ")"
+		}
+		return fmt.Sprintf("return &config.DecodeError{Field: %q, Var: %q, Err: %s}", f.Path, f.Var, err)
+	}
+
+	g.printf("{\n")
+	g.printf("v, err := config.Value(src, %q, %q)\n", f.Var, f.Tag.Get("default"))
+	g.printf("if err != nil {\n%s\n}\n", fail("err"))
+
+	if f.Tag.Get("required") == "true" {
+		g.printf("if v == \"\" {\n")
+		g.printf("errs = append(errs, &config.DecodeError{Field: %q, Var: %q, Err: config.ErrRequired})\n}\n", f.Path, f.Var)
+	}
+
+	if f.Tag.Get("file") == "true" {
+		g.printf("if v != \"\" {\nif v, err = config.ReadFile(src, v); err != nil {\n%s\n}\n}\n", fail("err"))
+	}
+
+	if err := g.assign(target, f.Type, presets, fail, 0); err != nil {
+		return fmt.Errorf("%s: %v", f.Path, err)
+	}
+	g.printf("}\n")
+
+	return nil
+}
+
+// assign writes the statements decoding v into target.
+func (g *generator) assign(target string, t ast.Expr, presets bool, fail func(string) string, depth int) error {
+	switch t := t.(type) {
+	case *ast.StarExpr:
+		g.printf("if %s == nil {\n%s = new(%s)\n}\n", target, target, g.typeString(t.X))
+		if g.unmarshaler(t.X) {
+			return g.unmarshal(target, presets, fail)
+		}
+		return g.assign("*"+target, t.X, presets, fail, depth)
+	case *ast.ArrayType:
+		if t.Len != nil {
+			return fmt.Errorf("arrays are not supported")
+		}
+		return g.slice(target, g.typeString(t), t.Elt, presets, fail, depth)
+	case *ast.MapType:
+		return g.mapping(target, g.typeString(t), t.Key, t.Value, fail, depth)
+	}
+
+	if g.unmarshaler(t) {
+		return g.unmarshal(target, presets, fail)
+	}
+
+	typ := g.typeString(t)
+	under, err := g.underlying(t)
+	if err != nil {
+		return err
+	}
+
+	switch u := under.(type) {
+	case *ast.ArrayType:
+		if u.Len == nil {
+			return g.slice(target, typ, u.Elt, presets, fail, depth)
+		}
+	case *ast.MapType:
+		return g.mapping(target, typ, u.Key, u.Value, fail, depth)
+	case *ast.Ident:
+		return g.basic(target, typ, u.Name, presets, fail)
+	}
+
+	return fmt.Errorf("type %s is not supported", typ)
+}
+
+func (g *generator) basic(target, typ, kind string, presets bool, fail func(string) string) error {
+	parse, ok := parsers[kind]
+	if !ok {
+		return fmt.Errorf("type %s is not supported", typ)
+	}
+
+	if parse == "" {
+		if presets {
+			g.printf("if v != \"\" {\n")
+		}
+		if typ == "string" {
+			g.printf("%s = v\n", target)
+		} else {
+			g.printf("%s = %s(v)\n", target, typ)
+		}
+		if presets {
+			g.printf("}\n")
+		}
+		return nil
+	}
+
+	g.imports["strconv"] = true
+
+	if presets {
+		g.printf("if v != \"\" {\n")
+	} else {
+		zero := "0"
+		if kind == "bool" {
+			zero = "false"
+		}
+		g.printf("if v == \"\" {\n%s = %s\n} else {\n", target, zero)
+	}
+	g.printf("n, err := %s\nif err != nil {\n%s\n}\n", parse, fail("err"))
+	g.printf("%s = %s(n)\n}\n", target, typ)
+
+	return nil
+}
+
+func (g *generator) unmarshal(target string, presets bool, fail func(string) string) error {
+	if strings.HasPrefix(target, "*") {
+		target = "(" + target + ")"
+	}
+
+	if presets {
+		g.printf("if v != \"\" {\n")
+	}
+	g.printf("if err := %s.UnmarshalENV([]byte(v)); err != nil {\n%s\n}\n", target, fail("err"))
+	if presets {
+		g.printf("}\n")
+	}
+
+	return nil
+}
+
+func (g *generator) slice(target, typ string, elem ast.Expr, presets bool, fail func(string) string, depth int) error {
+	g.imports["strings"] = true
+
+	s, i := fmt.Sprintf("s%d", depth), fmt.Sprintf("i%d", depth)
+	if presets {
+		g.printf("if v != \"\" {\n")
+	} else {
+		g.printf("if v == \"\" {\n%s = nil\n} else {\n", target)
+	}
+	g.printf("%s := strings.Split(v, \",\")\n", s)
+	g.printf("%s = make(%s, len(%s))\n", target, typ, s)
+	g.printf("for %s, v := range %s {\n", i, s)
+	if err := g.element(fmt.Sprintf("%s[%s]", target, i), elem, presets, fail, depth); err != nil {
+		return err
+	}
+	g.printf("}\n}\n")
+
+	return nil
+}
+
+func (g *generator) mapping(target, typ string, key, elem ast.Expr, fail func(string) string, depth int) error {
+	if id, ok := key.(*ast.Ident); !ok || id.Name != "string" {
+		return fmt.Errorf("map keys must be strings")
+	}
+
+	g.imports["errors"] = true
+	g.imports["strings"] = true
+
+	m, e := fmt.Sprintf("m%d", depth), fmt.Sprintf("e%d", depth)
+	g.printf("if v != \"\" {\n")
+	g.printf("%s := make(%s)\n", m, typ)
+	g.printf("for _, kv := range strings.Split(v, \",\") {\n")
+	g.printf("k, v, ok := strings.Cut(kv, \":\")\n")
+	g.printf("if !ok {\n%s\n}\n", fail(`errors.New("missing \":\" in map entry")`))
+	g.printf("var %s %s\n", e, g.typeString(elem))
+	if err := g.element(e, elem, false, fail, depth); err != nil {
+		return err
+	}
+	g.printf("%s[k] = %s\n}\n%s = %s\n}\n", m, e, target, m)
+
+	return nil
+}
+
+func (g *generator) element(target string, elem ast.Expr, presets bool, fail func(string) string, depth int) error {
+	if _, ok := g.pkg.Type(typeName(elem)); ok && !g.unmarshaler(elem) {
+		if under, _ := g.underlying(elem); under != nil {
+			if _, ok := under.(*ast.StructType); ok {
+				return fmt.Errorf("struct elements are not supported")
+			}
+		}
+	}
+
+	return g.assign(target, elem, presets, fail, depth+1)
+}
+
+// unmarshaler reports whether values of t are decoded by UnmarshalENV.
+// Types from other packages are assumed to implement it.
+func (g *generator) unmarshaler(t ast.Expr) bool {
+	switch t := t.(type) {
+	case *ast.Ident:
+		return g.pkg.HasMethod(t.Name, "UnmarshalENV")
+	case *ast.SelectorExpr:
+		return !isConfig(t, "Secret")
+	case *ast.IndexExpr, *ast.IndexListExpr:
+		return true
+	}
+
+	return false
+}
+
+// underlying resolves named types declared in the package.
+func (g *generator) underlying(t ast.Expr) (ast.Expr, error) {
+	for i := 0; i < 16; i++ {
+		switch tt := t.(type) {
+		case *ast.SelectorExpr:
+			if isConfig(tt, "Secret") {
+				return ast.NewIdent("string"), nil
+			}
+			return nil, fmt.Errorf("type %s does not implement config.Unmarshaler", g.typeString(tt))
+		case *ast.Ident:
+			if _, ok := parsers[tt.Name]; ok {
+				return tt, nil
+			}
+
+			ts, ok := g.pkg.Type(tt.Name)
+			if !ok {
+				return nil, fmt.Errorf("type %s is not supported", tt.Name)
+			}
+			t = ts.Type
+		default:
+			return t, nil
+		}
+	}
+
+	return nil, fmt.Errorf("type %s is not supported", g.typeString(t))
+}
+
+// typeString prints t for use in the generated file, recording the packages
+// it refers to.
+func (g *generator) typeString(t ast.Expr) string {
+	ast.Inspect(t, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok {
+			if id, ok := sel.X.(*ast.Ident); ok {
+				if path, ok := g.pkg.Imports[id.Name]; ok {
+					g.imports[path] = true
+				}
+			}
+			return false
+		}
+		return true
+	})
+
+	var b bytes.Buffer
+	printer.Fprint(&b, g.pkg.Fset, t)
+	return b.String()
+}
+
+func isConfig(sel *ast.SelectorExpr, name string) bool {
+	id, ok := sel.X.(*ast.Ident)
+	return ok && id.Name == "config" && sel.Sel.Name == name
+}
+
+func typeName(t ast.Expr) string {
+	if id, ok := t.(*ast.Ident); ok {
+		return id.Name
+	}
+	if star, ok := t.(*ast.StarExpr); ok {
+		return typeName(star.X)
+	}
+
+	return ""
+}
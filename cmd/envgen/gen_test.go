@@ -0,0 +1,43 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"testing"
+)
+
+func TestGenerateUpToDate(t *testing.T) {
+	src, err := generate("../../internal/envgentest", []string{"Config"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want, err := os.ReadFile("../../internal/envgentest/config_envgen.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(src, want) {
+		t.Error("config_envgen.go is stale; run go generate ./internal/envgentest")
+	}
+}
+
+func TestGenerateUnsupported(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(dir+"/config.go", []byte(`package x
+
+type Config struct {
+	Port int `+"`env:\"PORT\" min:\"1\"`"+`
+}
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := generate(dir, []string{"Config"}); err == nil || err.Error() != "Port: min tag is not supported" {
+		t.Error("unsupported tag", err)
+	}
+
+	if _, err := generate(dir, []string{"Missing"}); err == nil {
+		t.Error("missing type")
+	}
+}
@@ -0,0 +1,53 @@
+// Command envgen writes reflection-free DecodeENV methods for config structs.
+//
+// Usage:
+//
+//	//go:generate go run github.com/GMTror/config/cmd/envgen -type Config
+//
+// The generated method reads the same variables, defaults and file tags as
+// config.ReadENV, which calls it instead of walking the struct by reflection.
+// Fields of types from other packages must implement config.Unmarshaler.
+// Constraint and optional tags are not supported; use ReadENV for those.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func main() {
+	types := flag.String("type", "", "comma-separated list of struct type names")
+	output := flag.String("output", "", "output file name; default <type>_envgen.go")
+	flag.Parse()
+
+	if *types == "" {
+		fmt.Fprintln(os.Stderr, "envgen: -type is required")
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	dir := "."
+	if flag.NArg() > 0 {
+		dir = flag.Arg(0)
+	}
+
+	names := strings.Split(*types, ",")
+	src, err := generate(dir, names)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "envgen:", err)
+		os.Exit(1)
+	}
+
+	name := *output
+	if name == "" {
+		name = filepath.Join(dir, strings.ToLower(names[0])+"_envgen.go")
+	}
+
+	if err := os.WriteFile(name, src, 0644); err != nil {
+		fmt.Fprintln(os.Stderr, "envgen:", err)
+		os.Exit(1)
+	}
+}
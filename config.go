@@ -3,7 +3,7 @@ package config
 import (
 	"bytes"
 	"errors"
-	"os"
+	"fmt"
 	"reflect"
 	"strconv"
 	"strings"
@@ -15,12 +15,17 @@ const (
 	tagName      = "env"
 	valName      = "default"
 	pasName      = "-"
+	filName      = "file"
+	fileSuffix   = "_FILE"
 )
 
 type Unmarshaler interface {
 	UnmarshalENV([]byte) error
 }
 
+// getTag joins a parent prefix and a field's env name. Structs tagged
+// `env:",squash"`, and untagged ones, read their fields under the parent
+// prefix; tagged structs, with or without ",inline", add their name to it.
 func getTag(t1, t2 string) string {
 	if t1 != "" {
 		if t2 != "" {
@@ -33,75 +38,158 @@ func getTag(t1, t2 string) string {
 	}
 }
 
-func ReadENV(i interface{}) error {
-	if err := decode(reflect.ValueOf(i), "", ""); err != nil {
+type decoder struct {
+	*options
+	path    []string
+	types   []reflect.Type
+	errs    Errors
+	presets bool
+}
+
+func newDecoder(opts []Option) *decoder {
+	o := newOptions(opts)
+	return &decoder{options: o, presets: o.presets}
+}
+
+func ReadENV(i interface{}, opts ...Option) (err error) {
+	if v := reflect.ValueOf(i); v.Kind() != reflect.Ptr || v.IsNil() {
+		return errors.New("ReadENV: non-nil pointer required")
+	}
+
+	d := newDecoder(opts)
+	defer func() {
+		if r := recover(); r != nil {
+			err = d.panicError(r)
+		}
+	}()
+
+	if ok, err := d.decodeGenerated(i); err != nil {
 		return err
+	} else if !ok {
+		if err := d.decode(reflect.ValueOf(i), "", ""); err != nil {
+			return err
+		}
+	}
+
+	if d.unknown != nil {
+		d.checkUnknown(reflect.TypeOf(i), *d.unknown)
+	}
+
+	if len(d.errs) > 0 {
+		return d.errs
 	}
 
 	return nil
 }
 
-func decode(result reflect.Value, tag, defaultVal string) error {
-	switch result.Kind() {
+// panicError turns a panic raised while decoding, by reflection or by user
+// hooks, into an error for the field being decoded.
+func (d *decoder) panicError(r interface{}) error {
+	err, ok := r.(error)
+	if !ok {
+		err = fmt.Errorf("%v", r)
+	}
+
+	return &DecodeError{Field: strings.Join(d.path, "."), Err: fmt.Errorf("panic: %w", err)}
+}
+
+func (d *decoder) value(tag, defaultVal string) (string, error) {
+	val, err := d.resolve(tag, nil)
+	if err != nil {
+		return "", err
+	}
+
+	if val == "" && defaultVal != "" {
+		if d.expand && tag != "" {
+			return d.interpolate(defaultVal, nil)
+		}
+
+		val = defaultVal
+	}
+
+	return val, nil
+}
+
+// zero clears a field whose variable and default are both unset, unless
+// values already in the struct are kept as presets.
+func (d *decoder) zero(result reflect.Value) {
+	if !d.presets {
+		result.Set(reflect.Zero(result.Type()))
+	}
+}
+
+func (d *decoder) error(tag string, err error) error {
+	if _, ok := err.(*DecodeError); ok {
+		return err
+	}
+
+	return &DecodeError{Field: strings.Join(d.path, "."), Var: tag, Err: err}
+}
+
+func (d *decoder) decode(result reflect.Value, tag, defaultVal string) error {
+	return decoderFor(result.Type())(d, result, tag, defaultVal)
+}
+
+func kindDecoder(t reflect.Type) decodeFunc {
+	switch t.Kind() {
 	case reflect.Int:
-		return decodeInt(result, tag, defaultVal)
+		return (*decoder).decodeInt
 	case reflect.Int8:
-		return decodeInt8(result, tag, defaultVal)
+		return (*decoder).decodeInt8
 	case reflect.Int16:
-		return decodeInt16(result, tag, defaultVal)
+		return (*decoder).decodeInt16
 	case reflect.Int32:
-		return decodeInt32(result, tag, defaultVal)
+		return (*decoder).decodeInt32
 	case reflect.Int64:
-		return decodeInt64(result, tag, defaultVal)
+		return (*decoder).decodeInt64
 	case reflect.Uint:
-		return decodeUint(result, tag, defaultVal)
+		return (*decoder).decodeUint
 	case reflect.Uint8:
-		return decodeUint8(result, tag, defaultVal)
+		return (*decoder).decodeUint8
 	case reflect.Uint16:
-		return decodeUint16(result, tag, defaultVal)
+		return (*decoder).decodeUint16
 	case reflect.Uint32:
-		return decodeUint32(result, tag, defaultVal)
+		return (*decoder).decodeUint32
 	case reflect.Uint64:
-		return decodeUint64(result, tag, defaultVal)
+		return (*decoder).decodeUint64
 	case reflect.Float32:
-		return decodeFloat32(result, tag, defaultVal)
+		return (*decoder).decodeFloat32
 	case reflect.Float64:
-		return decodeFloat64(result, tag, defaultVal)
+		return (*decoder).decodeFloat64
 	case reflect.String:
-		return decodeString(result, tag, defaultVal)
+		return (*decoder).decodeString
 	case reflect.Bool:
-		return decodeBool(result, tag, defaultVal)
+		return (*decoder).decodeBool
 	// case reflect.Complex64:
-	// 	return decodeComplex64(result, tag, defaultVal)
+	// 	return (*decoder).decodeComplex64
 	// case reflect.Complex128:
-	// 	return decodeComplex128(result, tag, defaultVal)
+	// 	return (*decoder).decodeComplex128
 	// case reflect.Interface:
-	// 	return decodeInterface(result, tag, defaultVal)
+	// 	return (*decoder).decodeInterface
 	case reflect.Ptr:
-		return decodePtr(result, tag, defaultVal)
+		return (*decoder).decodePtr
 	case reflect.Struct:
-		return decodeStruct(result, tag, defaultVal)
+		return (*decoder).decodeStruct
 	case reflect.Slice:
-		return decodeSlice(result, tag, defaultVal)
+		return (*decoder).decodeSlice
 	case reflect.Map:
-		return decodeMap(result, tag, defaultVal)
+		return (*decoder).decodeMap
 	default:
-		return errors.New("type error")
+		return func(d *decoder, result reflect.Value, tag, defaultVal string) error {
+			return errors.New("type error")
+		}
 	}
-
-	return nil
 }
 
-func decodeInt(result reflect.Value, tag, defaultVal string) error {
-	tVal := os.Getenv(tag)
+func (d *decoder) decodeInt(result reflect.Value, tag, defaultVal string) error {
+	tVal, err := d.value(tag, defaultVal)
+	if err != nil {
+		return err
+	}
 
 	if tVal == "" {
-		if defaultVal != "" {
-			tVal = defaultVal
-		} else {
-			result.Set(reflect.ValueOf(0).Convert(result.Type()))
-			return nil
-		}
+		d.zero(result)
+		return nil
 	}
 
 	val, err := strconv.Atoi(tVal)
@@ -114,16 +202,15 @@ func decodeInt(result reflect.Value, tag, defaultVal string) error {
 	return nil
 }
 
-func decodeInt8(result reflect.Value, tag, defaultVal string) error {
-	tVal := os.Getenv(tag)
+func (d *decoder) decodeInt8(result reflect.Value, tag, defaultVal string) error {
+	tVal, err := d.value(tag, defaultVal)
+	if err != nil {
+		return err
+	}
 
 	if tVal == "" {
-		if defaultVal != "" {
-			tVal = defaultVal
-		} else {
-			result.Set(reflect.ValueOf(0).Convert(result.Type()))
-			return nil
-		}
+		d.zero(result)
+		return nil
 	}
 
 	val, err := strconv.ParseInt(tVal, 10, 8)
@@ -136,16 +223,15 @@ func decodeInt8(result reflect.Value, tag, defaultVal string) error {
 	return nil
 }
 
-func decodeInt16(result reflect.Value, tag, defaultVal string) error {
-	tVal := os.Getenv(tag)
+func (d *decoder) decodeInt16(result reflect.Value, tag, defaultVal string) error {
+	tVal, err := d.value(tag, defaultVal)
+	if err != nil {
+		return err
+	}
 
 	if tVal == "" {
-		if defaultVal != "" {
-			tVal = defaultVal
-		} else {
-			result.Set(reflect.ValueOf(0).Convert(result.Type()))
-			return nil
-		}
+		d.zero(result)
+		return nil
 	}
 
 	val, err := strconv.ParseInt(tVal, 10, 16)
@@ -158,16 +244,15 @@ func decodeInt16(result reflect.Value, tag, defaultVal string) error {
 	return nil
 }
 
-func decodeInt32(result reflect.Value, tag, defaultVal string) error {
-	tVal := os.Getenv(tag)
+func (d *decoder) decodeInt32(result reflect.Value, tag, defaultVal string) error {
+	tVal, err := d.value(tag, defaultVal)
+	if err != nil {
+		return err
+	}
 
 	if tVal == "" {
-		if defaultVal != "" {
-			tVal = defaultVal
-		} else {
-			result.Set(reflect.ValueOf(0).Convert(result.Type()))
-			return nil
-		}
+		d.zero(result)
+		return nil
 	}
 
 	val, err := strconv.ParseInt(tVal, 10, 32)
@@ -180,16 +265,15 @@ func decodeInt32(result reflect.Value, tag, defaultVal string) error {
 	return nil
 }
 
-func decodeInt64(result reflect.Value, tag, defaultVal string) error {
-	tVal := os.Getenv(tag)
+func (d *decoder) decodeInt64(result reflect.Value, tag, defaultVal string) error {
+	tVal, err := d.value(tag, defaultVal)
+	if err != nil {
+		return err
+	}
 
 	if tVal == "" {
-		if defaultVal != "" {
-			tVal = defaultVal
-		} else {
-			result.Set(reflect.ValueOf(0).Convert(result.Type()))
-			return nil
-		}
+		d.zero(result)
+		return nil
 	}
 
 	val, err := strconv.ParseInt(tVal, 10, 64)
@@ -202,16 +286,15 @@ func decodeInt64(result reflect.Value, tag, defaultVal string) error {
 	return nil
 }
 
-func decodeUint(result reflect.Value, tag, defaultVal string) error {
-	tVal := os.Getenv(tag)
+func (d *decoder) decodeUint(result reflect.Value, tag, defaultVal string) error {
+	tVal, err := d.value(tag, defaultVal)
+	if err != nil {
+		return err
+	}
 
 	if tVal == "" {
-		if defaultVal != "" {
-			tVal = defaultVal
-		} else {
-			result.Set(reflect.ValueOf(0).Convert(result.Type()))
-			return nil
-		}
+		d.zero(result)
+		return nil
 	}
 
 	val, err := strconv.ParseUint(tVal, 10, 32)
@@ -224,16 +307,15 @@ func decodeUint(result reflect.Value, tag, defaultVal string) error {
 	return nil
 }
 
-func decodeUint8(result reflect.Value, tag, defaultVal string) error {
-	tVal := os.Getenv(tag)
+func (d *decoder) decodeUint8(result reflect.Value, tag, defaultVal string) error {
+	tVal, err := d.value(tag, defaultVal)
+	if err != nil {
+		return err
+	}
 
 	if tVal == "" {
-		if defaultVal != "" {
-			tVal = defaultVal
-		} else {
-			result.Set(reflect.ValueOf(0).Convert(result.Type()))
-			return nil
-		}
+		d.zero(result)
+		return nil
 	}
 
 	val, err := strconv.ParseUint(tVal, 10, 8)
@@ -246,16 +328,15 @@ func decodeUint8(result reflect.Value, tag, defaultVal string) error {
 	return nil
 }
 
-func decodeUint16(result reflect.Value, tag, defaultVal string) error {
-	tVal := os.Getenv(tag)
+func (d *decoder) decodeUint16(result reflect.Value, tag, defaultVal string) error {
+	tVal, err := d.value(tag, defaultVal)
+	if err != nil {
+		return err
+	}
 
 	if tVal == "" {
-		if defaultVal != "" {
-			tVal = defaultVal
-		} else {
-			result.Set(reflect.ValueOf(0).Convert(result.Type()))
-			return nil
-		}
+		d.zero(result)
+		return nil
 	}
 
 	val, err := strconv.ParseUint(tVal, 10, 16)
@@ -268,16 +349,15 @@ func decodeUint16(result reflect.Value, tag, defaultVal string) error {
 	return nil
 }
 
-func decodeUint32(result reflect.Value, tag, defaultVal string) error {
-	tVal := os.Getenv(tag)
+func (d *decoder) decodeUint32(result reflect.Value, tag, defaultVal string) error {
+	tVal, err := d.value(tag, defaultVal)
+	if err != nil {
+		return err
+	}
 
 	if tVal == "" {
-		if defaultVal != "" {
-			tVal = defaultVal
-		} else {
-			result.Set(reflect.ValueOf(0).Convert(result.Type()))
-			return nil
-		}
+		d.zero(result)
+		return nil
 	}
 
 	val, err := strconv.ParseUint(tVal, 10, 32)
@@ -290,16 +370,15 @@ func decodeUint32(result reflect.Value, tag, defaultVal string) error {
 	return nil
 }
 
-func decodeUint64(result reflect.Value, tag, defaultVal string) error {
-	tVal := os.Getenv(tag)
+func (d *decoder) decodeUint64(result reflect.Value, tag, defaultVal string) error {
+	tVal, err := d.value(tag, defaultVal)
+	if err != nil {
+		return err
+	}
 
 	if tVal == "" {
-		if defaultVal != "" {
-			tVal = defaultVal
-		} else {
-			result.Set(reflect.ValueOf(0).Convert(result.Type()))
-			return nil
-		}
+		d.zero(result)
+		return nil
 	}
 
 	val, err := strconv.ParseUint(tVal, 10, 64)
@@ -312,16 +391,15 @@ func decodeUint64(result reflect.Value, tag, defaultVal string) error {
 	return nil
 }
 
-func decodeFloat32(result reflect.Value, tag, defaultVal string) error {
-	tVal := os.Getenv(tag)
+func (d *decoder) decodeFloat32(result reflect.Value, tag, defaultVal string) error {
+	tVal, err := d.value(tag, defaultVal)
+	if err != nil {
+		return err
+	}
 
 	if tVal == "" {
-		if defaultVal != "" {
-			tVal = defaultVal
-		} else {
-			result.Set(reflect.ValueOf(0).Convert(result.Type()))
-			return nil
-		}
+		d.zero(result)
+		return nil
 	}
 
 	val, err := strconv.ParseFloat(tVal, 32)
@@ -334,16 +412,15 @@ func decodeFloat32(result reflect.Value, tag, defaultVal string) error {
 	return nil
 }
 
-func decodeFloat64(result reflect.Value, tag, defaultVal string) error {
-	tVal := os.Getenv(tag)
+func (d *decoder) decodeFloat64(result reflect.Value, tag, defaultVal string) error {
+	tVal, err := d.value(tag, defaultVal)
+	if err != nil {
+		return err
+	}
 
 	if tVal == "" {
-		if defaultVal != "" {
-			tVal = defaultVal
-		} else {
-			result.Set(reflect.ValueOf(0).Convert(result.Type()))
-			return nil
-		}
+		d.zero(result)
+		return nil
 	}
 
 	val, err := strconv.ParseFloat(tVal, 64)
@@ -359,10 +436,15 @@ func decodeFloat64(result reflect.Value, tag, defaultVal string) error {
 // func decodeComplex64(result reflect.Value, tag, defaultVal string) error {}
 // func decodeComplex128(result reflect.Value, tag, defaultVal string) error {}
 
-func decodeString(result reflect.Value, tag, defaultVal string) error {
-	val := os.Getenv(tag)
+func (d *decoder) decodeString(result reflect.Value, tag, defaultVal string) error {
+	val, err := d.value(tag, defaultVal)
+	if err != nil {
+		return err
+	}
+
 	if val == "" {
-		val = defaultVal
+		d.zero(result)
+		return nil
 	}
 
 	result.Set(reflect.ValueOf(val).Convert(result.Type()))
@@ -370,16 +452,15 @@ func decodeString(result reflect.Value, tag, defaultVal string) error {
 	return nil
 }
 
-func decodeBool(result reflect.Value, tag, defaultVal string) error {
-	tVal := os.Getenv(tag)
+func (d *decoder) decodeBool(result reflect.Value, tag, defaultVal string) error {
+	tVal, err := d.value(tag, defaultVal)
+	if err != nil {
+		return err
+	}
 
 	if tVal == "" {
-		if defaultVal != "" {
-			tVal = defaultVal
-		} else {
-			result.Set(reflect.ValueOf(false).Convert(result.Type()))
-			return nil
-		}
+		d.zero(result)
+		return nil
 	}
 
 	val, err := strconv.ParseBool(tVal)
@@ -394,7 +475,10 @@ func decodeBool(result reflect.Value, tag, defaultVal string) error {
 
 // func decodeInterface(result reflect.Value, tag, defaultVal string) error {}
 
-func decodePtr(result reflect.Value, tag, defaultVal string) error {
+func (d *decoder) decodePtr(result reflect.Value, tag, defaultVal string) error {
+	if d.tooDeep(result.Type().Elem()) {
+		return nil
+	}
 
 	if result.IsNil() {
 		resultType := result.Type()
@@ -402,16 +486,11 @@ func decodePtr(result reflect.Value, tag, defaultVal string) error {
 		resultNewType := reflect.New(resultElemType)
 
 		if u, ok := resultNewType.Interface().(Unmarshaler); ok {
-			val := os.Getenv(tag)
-			if val == "" {
-				val = defaultVal
-			}
-
-			if err := u.UnmarshalENV(bytes.NewBufferString(val).Bytes()); err != nil {
+			if err := d.unmarshal(u, tag, defaultVal); err != nil {
 				return err
 			}
 		} else {
-			if err := decode(reflect.Indirect(resultNewType), tag, defaultVal); err != nil {
+			if err := d.decode(reflect.Indirect(resultNewType), tag, defaultVal); err != nil {
 				return err
 			}
 		}
@@ -419,16 +498,11 @@ func decodePtr(result reflect.Value, tag, defaultVal string) error {
 		result.Set(resultNewType)
 	} else {
 		if u, ok := result.Interface().(Unmarshaler); ok {
-			val := os.Getenv(tag)
-			if val == "" {
-				val = defaultVal
-			}
-
-			if err := u.UnmarshalENV(bytes.NewBufferString(val).Bytes()); err != nil {
+			if err := d.unmarshal(u, tag, defaultVal); err != nil {
 				return err
 			}
 		} else {
-			if err := decode(reflect.Indirect(result), tag, defaultVal); err != nil {
+			if err := d.decode(reflect.Indirect(result), tag, defaultVal); err != nil {
 				return err
 			}
 		}
@@ -437,41 +511,137 @@ func decodePtr(result reflect.Value, tag, defaultVal string) error {
 	return nil
 }
 
-func decodeStruct(result reflect.Value, tag, defaultVal string) error {
-	resultType := result.Type()
-	for i := 0; i < resultType.NumField(); i++ {
-		fieldType := resultType.Field(i)
-		bTag := fieldType.Tag.Get(tagName)
-		if bTag != pasName {
-			sTag := getTag(tag, bTag)
-			sVal := fieldType.Tag.Get(valName)
-			if err := decode(result.Field(i), sTag, sVal); err != nil {
-				return err
+func (d *decoder) unmarshal(u Unmarshaler, tag, defaultVal string) error {
+	val, err := d.value(tag, defaultVal)
+	if err != nil {
+		return err
+	}
+
+	if val == "" && d.presets {
+		return nil
+	}
+
+	return u.UnmarshalENV(bytes.NewBufferString(val).Bytes())
+}
+
+func (d *decoder) decodeStruct(result reflect.Value, tag, defaultVal string) error {
+	t := result.Type()
+	if err := d.recursive(t); err != nil {
+		return err
+	}
+
+	p := planFor(t, tag)
+	if err := p.conflicts(t); err != nil {
+		return d.error("", err)
+	}
+
+	d.types = append(d.types, t)
+	defer func() { d.types = d.types[:len(d.types)-1] }()
+
+	if p.defaulter && !d.merge && result.CanAddr() && result.CanInterface() {
+		result.Addr().Interface().(Defaulter).SetDefaults()
+		defer func(presets bool) { d.presets = presets }(d.presets)
+		d.presets = true
+	}
+
+	for i := range p.fields {
+		f := &p.fields[i]
+		fv := result.Field(f.index)
+		sVal := f.def
+		if d.presets && d.presetsFirst && f.leaf && !fv.IsZero() {
+			sVal = ""
+		}
+		if f.err != nil {
+			d.path = append(d.path, f.field.Name)
+			return d.error(f.tag, f.err)
+		}
+		if d.optional(fv, f) {
+			continue
+		}
+
+		d.path = append(d.path, f.field.Name)
+
+		name, err := d.alias(f)
+		if err == nil {
+			err = d.decodeField(fv, f, name, sVal)
+		}
+		if err != nil {
+			if f.secret {
+				err = redactError(err)
 			}
+
+			return d.error(name, err)
 		}
+		d.check(fv, f)
+		d.path = d.path[:len(d.path)-1]
+	}
+
+	if p.validator {
+		d.validate(result)
 	}
 
 	return nil
 }
 
-func decodeSlice(result reflect.Value, tag, defaultVal string) error {
+func (d *decoder) decodeField(result reflect.Value, f *fieldPlan, tag, defaultVal string) error {
+	if f.required && f.leaf {
+		val, err := d.value(tag, defaultVal)
+		if err != nil {
+			return err
+		}
+
+		if val == "" && !(d.presets && !result.IsZero()) {
+			d.errs = append(d.errs, d.error(tag, ErrRequired))
+		}
+	}
+
+	if !f.file {
+		return f.decode(d, result, tag, defaultVal)
+	}
+
+	path, err := d.value(tag, defaultVal)
+	if err != nil {
+		return err
+	}
+
+	if path == "" {
+		return f.decode(d, result, "", "")
+	}
+
+	val, err := d.readFile(path)
+	if err != nil {
+		return err
+	}
+
+	return f.decode(d, result, "", val)
+}
+
+func (d *decoder) decodeSlice(result reflect.Value, tag, defaultVal string) error {
 	resultType := result.Type()
 	resultElemType := resultType.Elem()
 	resultSliceType := reflect.SliceOf(resultElemType)
 
 	rs := reflect.MakeSlice(resultSliceType, 0, 0)
 
-	tVal := os.Getenv(tag)
+	tVal, err := d.value(tag, defaultVal)
+	if err != nil {
+		return err
+	}
 
 	if tVal == "" {
-		if defaultVal != "" {
-			tVal = defaultVal
-		}
+		d.zero(result)
+		return nil
+	}
+
+	if d.appendSlices && d.present(tag) {
+		rs = reflect.AppendSlice(rs, result)
 	}
 
 	for _, val := range strings.Split(tVal, delimiterVal) {
 		r := reflect.Indirect(reflect.New(resultElemType))
-		decode(r, "", val)
+		if err := d.decode(r, "", val); err != nil {
+			return err
+		}
 		rs = reflect.Append(rs, r)
 	}
 
@@ -480,27 +650,36 @@ func decodeSlice(result reflect.Value, tag, defaultVal string) error {
 	return nil
 }
 
-func decodeMap(result reflect.Value, tag, defaultVal string) error {
+func (d *decoder) decodeMap(result reflect.Value, tag, defaultVal string) error {
 	resultType := result.Type()
 	resultElemType := resultType.Elem()
 	resultKeyType := resultType.Key()
 
 	rm := reflect.MakeMap(reflect.MapOf(resultKeyType, resultElemType))
 
-	tVal := os.Getenv(tag)
-
-	if tVal == "" {
-		if defaultVal != "" {
-			tVal = defaultVal
-		}
+	tVal, err := d.value(tag, defaultVal)
+	if err != nil {
+		return err
 	}
 
 	if tVal != "" {
+		if d.mergeMaps {
+			for iter := result.MapRange(); iter.Next(); {
+				rm.SetMapIndex(iter.Key(), iter.Value())
+			}
+		}
+
 		for _, kv := range strings.Split(tVal, delimiterVal) {
 			vs := strings.SplitN(kv, delimiterMap, 2)
+			if len(vs) != 2 {
+				return fmt.Errorf("missing %q in map entry", delimiterMap)
+			}
+
 			key := reflect.ValueOf(vs[0])
 			val := reflect.Indirect(reflect.New(resultElemType))
-			decode(val, "", vs[1])
+			if err := d.decode(val, "", vs[1]); err != nil {
+				return err
+			}
 			rm.SetMapIndex(key, val)
 		}
 
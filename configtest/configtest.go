@@ -0,0 +1,203 @@
+// Package configtest helps test code that reads its configuration with the
+// config package, without touching the process environment.
+package configtest
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/GMTror/config"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+// Load decodes a new T from env alone and fails the test on error. Options
+// are applied after the isolated source, so tests using it can run in
+// parallel.
+func Load[T any](t testing.TB, env map[string]string, opts ...config.Option) T {
+	t.Helper()
+
+	var v T
+	opts = append([]config.Option{config.WithSources(config.MapSource(env))}, opts...)
+	if err := config.ReadENV(&v, opts...); err != nil {
+		t.Fatalf("configtest: %v", err)
+	}
+
+	return v
+}
+
+// SetEnv sets the variables in env for the rest of the test and restores
+// their previous values when it ends. Like t.Setenv, it cannot be used in
+// parallel tests.
+func SetEnv(t testing.TB, env map[string]string) {
+	t.Helper()
+
+	for k, v := range env {
+		t.Setenv(k, v)
+	}
+}
+
+// Equal reports every field of got that differs from want, by field path.
+func Equal[T any](t testing.TB, want, got T) {
+	t.Helper()
+
+	for _, d := range Diff(want, got) {
+		t.Error(d)
+	}
+}
+
+// Diff lists the fields of got that differ from want as
+// "Path: got X, want Y", walking nested structs and pointers.
+func Diff(want, got interface{}) []string {
+	var diffs []string
+	diff(&diffs, "", reflect.ValueOf(want), reflect.ValueOf(got))
+	return diffs
+}
+
+func diff(diffs *[]string, path string, want, got reflect.Value) {
+	if !want.IsValid() || !got.IsValid() || want.Type() != got.Type() {
+		if !reflect.DeepEqual(value(want), value(got)) {
+			*diffs = append(*diffs, fmt.Sprintf("%s: got %s, want %s", name(path), format(got), format(want)))
+		}
+		return
+	}
+
+	switch want.Kind() {
+	case reflect.Ptr:
+		if want.IsNil() || got.IsNil() {
+			if want.IsNil() != got.IsNil() {
+				*diffs = append(*diffs, fmt.Sprintf("%s: got %s, want %s", name(path), format(got), format(want)))
+			}
+			return
+		}
+
+		diff(diffs, path, want.Elem(), got.Elem())
+		return
+	case reflect.Struct:
+		t := want.Type()
+		if hasExported(t) {
+			for i := 0; i < t.NumField(); i++ {
+				if f := t.Field(i); f.IsExported() {
+					p := f.Name
+					if path != "" {
+						p = path + "." + f.Name
+					}
+					diff(diffs, p, want.Field(i), got.Field(i))
+				}
+			}
+			return
+		}
+	}
+
+	if !reflect.DeepEqual(want.Interface(), got.Interface()) {
+		*diffs = append(*diffs, fmt.Sprintf("%s: got %s, want %s", name(path), format(got), format(want)))
+	}
+}
+
+func value(v reflect.Value) interface{} {
+	if !v.IsValid() {
+		return nil
+	}
+
+	return v.Interface()
+}
+
+func hasExported(t reflect.Type) bool {
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).IsExported() {
+			return true
+		}
+	}
+
+	return false
+}
+
+func name(path string) string {
+	if path == "" {
+		return "value"
+	}
+
+	return path
+}
+
+func format(v reflect.Value) string {
+	if !v.IsValid() {
+		return "<nil>"
+	}
+
+	if v.Kind() == reflect.Ptr && !v.IsNil() {
+		return "&" + format(v.Elem())
+	}
+
+	return fmt.Sprintf("%#v", v.Interface())
+}
+
+// Golden compares got with testdata/<name>.golden. Running the tests with
+// -update rewrites the file instead.
+func Golden(t testing.TB, name string, got []byte) {
+	t.Helper()
+
+	path := filepath.Join("testdata", name+".golden")
+	if *update {
+		if err := os.MkdirAll("testdata", 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, got, 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("configtest: %v (run with -update to create it)", err)
+	}
+
+	if !bytes.Equal(got, want) {
+		t.Errorf("configtest: output differs from %s (run with -update to accept it):\n%s", path, got)
+	}
+}
+
+// GoldenUsage compares the usage of i in the given format with a golden
+// file named after the test.
+func GoldenUsage(t testing.TB, i interface{}, format config.Format) {
+	t.Helper()
+
+	var b bytes.Buffer
+	if err := config.Usage(i, &b, format); err != nil {
+		t.Fatal(err)
+	}
+
+	Golden(t, goldenName(t.Name(), format), b.Bytes())
+}
+
+// GoldenDotEnv compares the .env template of i with a golden file named
+// after the test.
+func GoldenDotEnv(t testing.TB, i interface{}) {
+	t.Helper()
+
+	var b bytes.Buffer
+	if err := config.WriteDotEnvTemplate(i, &b); err != nil {
+		t.Fatal(err)
+	}
+
+	Golden(t, strings.ReplaceAll(t.Name(), "/", "_")+".env", b.Bytes())
+}
+
+func goldenName(test string, format config.Format) string {
+	name := strings.ReplaceAll(test, "/", "_")
+	switch format {
+	case config.FormatMarkdown:
+		return name + ".md"
+	case config.FormatJSON:
+		return name + ".json"
+	}
+
+	return name + ".txt"
+}
@@ -0,0 +1,103 @@
+package configtest
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/GMTror/config"
+)
+
+type Config struct {
+	Host    string        `env:"HOST" default:"localhost" description:"listen address"`
+	Port    int           `env:"PORT" default:"8080"`
+	Token   config.Secret `env:"TOKEN"`
+	Timeout *int          `env:"TIMEOUT"`
+	DB      struct {
+		Host string   `env:"HOST" required:"true"`
+		Tags []string `env:"TAGS"`
+	} `env:"DB"`
+}
+
+func TestLoad(t *testing.T) {
+	t.Parallel()
+
+	c := Load[Config](t, map[string]string{"PORT": "9000", "DB_HOST": "db", "DB_TAGS": "a,b"})
+
+	if c.Host != "localhost" || c.Port != 9000 {
+		t.Error("Load")
+	}
+
+	if c.DB.Host != "db" || !reflect.DeepEqual(c.DB.Tags, []string{"a", "b"}) {
+		t.Error("Load nested")
+	}
+}
+
+func TestLoadIsolated(t *testing.T) {
+	SetEnv(t, map[string]string{"DB_HOST": "from-env"})
+
+	c := Load[Config](t, map[string]string{"DB_HOST": "from-map"})
+
+	if c.DB.Host != "from-map" {
+		t.Error("Load reads the environment")
+	}
+}
+
+func TestSetEnv(t *testing.T) {
+	os.Unsetenv("CONFIGTEST_UNSET")
+	os.Setenv("CONFIGTEST_SET", "before")
+
+	t.Run("set", func(t *testing.T) {
+		SetEnv(t, map[string]string{"CONFIGTEST_UNSET": "a", "CONFIGTEST_SET": "b"})
+
+		if os.Getenv("CONFIGTEST_UNSET") != "a" || os.Getenv("CONFIGTEST_SET") != "b" {
+			t.Error("SetEnv")
+		}
+	})
+
+	if _, ok := os.LookupEnv("CONFIGTEST_UNSET"); ok {
+		t.Error("SetEnv restore unset")
+	}
+
+	if os.Getenv("CONFIGTEST_SET") != "before" {
+		t.Error("SetEnv restore set")
+	}
+}
+
+func TestDiff(t *testing.T) {
+	timeout := 5
+	want := Load[Config](t, map[string]string{"DB_HOST": "db", "TIMEOUT": "5"})
+	got := want
+	got.Port = 1
+	got.Token = "leaked"
+	got.Timeout = nil
+	got.DB.Tags = []string{"x"}
+
+	diffs := Diff(want, got)
+	expected := []string{
+		`Port: got 1, want 8080`,
+		`Token: got "[REDACTED]", want "[REDACTED]"`,
+		`Timeout: got (*int)(nil), want &5`,
+		`DB.Tags: got []string{"x"}, want []string(nil)`,
+	}
+
+	if !reflect.DeepEqual(diffs, expected) {
+		t.Errorf("Diff: %q", diffs)
+	}
+
+	got = want
+	got.Timeout = &timeout
+	if diffs := Diff(want, got); len(diffs) != 0 {
+		t.Errorf("Diff equal pointers: %q", diffs)
+	}
+
+	Equal(t, want, got)
+}
+
+func TestGoldenUsage(t *testing.T) {
+	GoldenUsage(t, &Config{}, config.FormatMarkdown)
+}
+
+func TestGoldenDotEnv(t *testing.T) {
+	GoldenDotEnv(t, &Config{})
+}
@@ -0,0 +1,9 @@
+package config
+
+// Defaulter is implemented by config structs that compute their own
+// defaults. SetDefaults is called before the struct's fields are decoded,
+// and the values it sets are kept unless a variable or default tag
+// overrides them.
+type Defaulter interface {
+	SetDefaults()
+}
@@ -0,0 +1,98 @@
+package config
+
+import (
+	"runtime"
+	"testing"
+)
+
+type Upstream struct {
+	Host string `env:"HOST"`
+	Port int    `env:"PORT"`
+}
+
+type ConfigDefaults struct {
+	Workers   int        `env:"WORKERS"`
+	Level     string     `env:"LEVEL" default:"info"`
+	Upstreams []Upstream `env:"-"`
+	Primary   Upstream   `env:"PRIMARY"`
+	Hosts     []string   `env:"HOSTS"`
+}
+
+func (c *ConfigDefaults) SetDefaults() {
+	c.Workers = runtime.NumCPU()
+	c.Level = "warn"
+	c.Upstreams = []Upstream{{Host: "a", Port: 1}}
+	c.Hosts = []string{"x"}
+}
+
+func (u *Upstream) SetDefaults() {
+	if u.Port == 0 {
+		u.Port = 80
+	}
+}
+
+type ConfigPresets struct {
+	Workers int    `env:"WORKERS"`
+	Level   string `env:"LEVEL" default:"info"`
+	Port    int    `env:"PORT"`
+}
+
+func TestSetDefaults(t *testing.T) {
+	c := &ConfigDefaults{}
+
+	if err := ReadENV(c, WithSources(MapSource{"PRIMARY_HOST": "p"})); err != nil {
+		t.Error(err)
+	}
+
+	if c.Workers != runtime.NumCPU() {
+		t.Error("set defaults WORKERS")
+	}
+
+	if c.Level != "info" {
+		t.Error("set defaults LEVEL tag over preset")
+	}
+
+	if len(c.Upstreams) != 1 || len(c.Hosts) != 1 {
+		t.Error("set defaults slices")
+	}
+
+	if c.Primary.Host != "p" || c.Primary.Port != 80 {
+		t.Error("set defaults nested PRIMARY", c.Primary)
+	}
+
+	c = &ConfigDefaults{}
+
+	if err := ReadENV(c, WithSources(MapSource{"WORKERS": "3", "HOSTS": "y,z"}), PresetsOverTags()); err != nil {
+		t.Error(err)
+	}
+
+	if c.Workers != 3 || len(c.Hosts) != 2 {
+		t.Error("set defaults env over preset")
+	}
+
+	if c.Level != "warn" {
+		t.Error("set defaults LEVEL preset over tag")
+	}
+}
+
+func TestKeepPresets(t *testing.T) {
+	c := &ConfigPresets{Workers: 4, Level: "debug", Port: 8080}
+
+	if err := ReadENV(c, WithSources(MapSource{"PORT": "9090"})); err != nil {
+		t.Error(err)
+	}
+
+	if c.Workers != 0 || c.Level != "info" || c.Port != 9090 {
+		t.Error("presets cleared", c)
+	}
+
+	c = &ConfigPresets{Workers: 4, Level: "debug", Port: 8080}
+
+	if err := ReadENV(c, WithSources(MapSource{"PORT": "9090"}), KeepPresets()); err != nil {
+		t.Error(err)
+	}
+
+	if c.Workers != 4 || c.Level != "info" || c.Port != 9090 {
+		t.Error("presets kept", c)
+	}
+}
@@ -0,0 +1,104 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// dataLink is the symlink Kubernetes points at the current version of a
+// mounted ConfigMap or Secret, and swaps atomically on update.
+const dataLink = "..data"
+
+type DirOption func(*dirOptions)
+
+type dirOptions struct {
+	prefix      string
+	keys        func(name string) string
+	trimNewline bool
+}
+
+// DirPrefix joins prefix to every key, as a struct tag prefix would.
+func DirPrefix(prefix string) DirOption {
+	return func(o *dirOptions) {
+		o.prefix = prefix
+	}
+}
+
+// DirKeys maps file names to keys. By default names are upper-cased with
+// dots, dashes and spaces turned into underscores.
+func DirKeys(fn func(name string) string) DirOption {
+	return func(o *dirOptions) {
+		o.keys = fn
+	}
+}
+
+// DirTrimNewline strips a single trailing newline from every value.
+func DirTrimNewline() DirOption {
+	return func(o *dirOptions) {
+		o.trimNewline = true
+	}
+}
+
+// DirSource serves a directory holding one file per key, as Kubernetes
+// mounts ConfigMaps and Secrets. Dotfiles and subdirectories are ignored.
+// When the directory has a ..data link, files are read through its target
+// so that a refresh never mixes two versions, and the watcher notices when
+// the link is swapped.
+func DirSource(path string, opts ...DirOption) (*FileSource, error) {
+	o := &dirOptions{keys: func(name string) string { return fileKey("", name) }}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return newSource(path, func(path string) (map[string]string, error) {
+		return readDir(path, o)
+	})
+}
+
+func readDir(path string, o *dirOptions) (map[string]string, error) {
+	dir := path
+	if target, err := os.Readlink(filepath.Join(path, dataLink)); err == nil {
+		if !filepath.IsAbs(target) {
+			target = filepath.Join(path, target)
+		}
+		dir = target
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]string, len(entries))
+	for _, e := range entries {
+		name := e.Name()
+		if strings.HasPrefix(name, ".") {
+			continue
+		}
+
+		file := filepath.Join(dir, name)
+		fi, err := os.Stat(file)
+		if err != nil {
+			return nil, err
+		}
+		if fi.IsDir() {
+			continue
+		}
+
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+
+		val := string(data)
+		if o.trimNewline {
+			val = strings.TrimSuffix(val, "\n")
+			val = strings.TrimSuffix(val, "\r")
+		}
+
+		values[getTag(o.prefix, o.keys(name))] = val
+	}
+
+	return values, nil
+}
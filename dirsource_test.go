@@ -0,0 +1,129 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+type ConfigDir struct {
+	DB struct {
+		Host string `env:"HOST"`
+		Port int    `env:"PORT"`
+	} `env:"DB"`
+	Token string `env:"TOKEN"`
+}
+
+// mountVersion lays out files the way the kubelet does: a hidden version
+// directory, a ..data link to it and one link per key through ..data.
+func mountVersion(t *testing.T, dir, version string, files map[string]string) {
+	t.Helper()
+
+	if err := os.Mkdir(filepath.Join(dir, version), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, val := range files {
+		writeFile(t, filepath.Join(dir, version, name), val)
+
+		link := filepath.Join(dir, name)
+		if _, err := os.Lstat(link); os.IsNotExist(err) {
+			if err := os.Symlink(filepath.Join(dataLink, name), link); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	tmp := filepath.Join(dir, "..data_tmp")
+	if err := os.Symlink(version, tmp); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.Rename(tmp, filepath.Join(dir, dataLink)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDirSource(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "db.host"), "pg\n")
+	writeFile(t, filepath.Join(dir, "DB_PORT"), "5432")
+	writeFile(t, filepath.Join(dir, ".hidden"), "x")
+	if err := os.Mkdir(filepath.Join(dir, "nested"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	src, err := DirSource(dir, DirTrimNewline())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keys := src.Keys()
+	if len(keys) != 2 {
+		t.Error("dir keys", keys)
+	}
+
+	c := &ConfigDir{}
+	if err := ReadENV(c, WithSources(src)); err != nil {
+		t.Error(err)
+	}
+
+	if c.DB.Host != "pg" || c.DB.Port != 5432 {
+		t.Error("dir values", c.DB)
+	}
+
+	src, err = DirSource(dir, DirPrefix("APP"), DirKeys(strings.ToLower))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if v, _ := src.Lookup("APP_db.host"); v != "pg\n" {
+		t.Error("dir prefix and keys", src.Keys())
+	}
+
+	if _, err := DirSource(filepath.Join(dir, "missing")); err == nil {
+		t.Error("dir missing")
+	}
+}
+
+func TestDirSourceSwap(t *testing.T) {
+	dir := t.TempDir()
+	mountVersion(t, dir, "..2024_01_01_v1", map[string]string{"db-host": "one", "token": "a"})
+
+	src, err := DirSource(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(src.Keys()) != 2 {
+		t.Error("mounted keys", src.Keys())
+	}
+
+	w, err := NewWatcher[ConfigDir](WithSources(src), PollInterval(10*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	events := make(chan Event[ConfigDir], 10)
+	w.Subscribe(func(e Event[ConfigDir]) {
+		events <- e
+	})
+
+	if c := w.Load(); c.DB.Host != "one" || c.Token != "a" {
+		t.Error("mounted values", c)
+	}
+
+	mountVersion(t, dir, "..2024_01_02_v2", map[string]string{"db-host": "two", "token": "a"})
+
+	select {
+	case e := <-events:
+		if e.Err != nil || e.New.DB.Host != "two" || len(e.Changed) != 1 || e.Changed[0] != "DB.Host" {
+			t.Error("swap event", e)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("swap event timeout")
+	}
+}
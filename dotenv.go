@@ -0,0 +1,15 @@
+package config
+
+import (
+	"io"
+	"reflect"
+
+	"github.com/GMTror/config/internal/render"
+)
+
+// WriteDotEnvTemplate writes a commented .env file listing every variable
+// read by ReadENV for i, pre-filled with its default. Secret fields are left
+// blank.
+func WriteDotEnvTemplate(i interface{}, w io.Writer) error {
+	return render.DotEnv(w, usage(reflect.TypeOf(i)))
+}
@@ -0,0 +1,59 @@
+package config
+
+import (
+	"bytes"
+	"testing"
+)
+
+type ConfigDotEnv struct {
+	Host     string `env:"HOST" default:"localhost" description:"listen host"`
+	Password string `env:"PASSWORD" default:"hunter2" secret:"true" description:"database password"`
+	Token    string `env:"TOKEN" required:"true" description:"api token\nissued by ops"`
+	Greeting string `env:"GREETING" default:"hello world"`
+	DB       struct {
+		Hosts []string `env:"HOSTS" default:"a,b"`
+	} `env:"DB"`
+	Skip string `env:"-"`
+}
+
+func TestWriteDotEnvTemplate(t *testing.T) {
+	var buf bytes.Buffer
+
+	if err := WriteDotEnvTemplate(&ConfigDotEnv{}, &buf); err != nil {
+		t.Error(err)
+	}
+
+	want := `# listen host
+# type: string
+HOST=localhost
+
+# database password
+# type: string
+PASSWORD=
+
+# api token
+# issued by ops
+# type: string (required)
+TOKEN=
+
+# type: string
+GREETING="hello world"
+
+# type: []string
+DB_HOSTS=a,b
+`
+
+	if buf.String() != want {
+		t.Errorf("dotenv template\n%s", buf.String())
+	}
+
+	var again bytes.Buffer
+
+	if err := WriteDotEnvTemplate(&ConfigDotEnv{}, &again); err != nil {
+		t.Error(err)
+	}
+
+	if again.String() != buf.String() {
+		t.Error("dotenv template not deterministic")
+	}
+}
@@ -0,0 +1,118 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/GMTror/config/internal/render"
+)
+
+type dumpEntry struct {
+	Field    string `json:"field"`
+	Name     string `json:"name"`
+	Value    string `json:"value"`
+	Source   string `json:"source"`
+	Redacted bool   `json:"redacted,omitempty"`
+}
+
+// Dump renders the effective values of i together with where each one came
+// from: a source, its <NAME>_FILE variant, the default tag, a preset value,
+// or nothing.
+// Secret fields are redacted, so the output is safe to expose.
+func Dump(i interface{}, format Format, opts ...Option) ([]byte, error) {
+	d := newDecoder(opts)
+	v := reflect.ValueOf(i)
+
+	var entries []dumpEntry
+	for _, f := range fields(v.Type()) {
+		fv, ok := fieldByIndex(v, f.Index)
+		e := dumpEntry{Field: f.Path, Name: f.Name, Source: d.provenance(f, ok && !fv.IsZero())}
+
+		if ok {
+			// Values that have no env form, such as Unmarshalers without
+			// MarshalENV, are shown as fmt prints them.
+			val, _, err := encode(fv)
+			if err != nil && fv.CanInterface() {
+				val = fmt.Sprint(fv.Interface())
+			}
+			e.Value = val
+		}
+
+		if f.Secret && e.Value != "" {
+			e.Value = redacted
+			e.Redacted = true
+		}
+
+		entries = append(entries, e)
+	}
+
+	var buf bytes.Buffer
+	switch format {
+	case FormatText:
+		tw := tabwriter.NewWriter(&buf, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "FIELD\tNAME\tVALUE\tSOURCE")
+		for _, e := range entries {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", e.Field, e.Name, e.Value, e.Source)
+		}
+		tw.Flush()
+	case FormatMarkdown:
+		buf.WriteString("| Field | Name | Value | Source |\n|-------|------|-------|--------|\n")
+		for _, e := range entries {
+			fmt.Fprintf(&buf, "| %s | `%s` | %s | %s |\n", e.Field, e.Name, render.Code(e.Value), e.Source)
+		}
+	case FormatJSON:
+		enc := json.NewEncoder(&buf)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(entries); err != nil {
+			return nil, err
+		}
+	case FormatYAML:
+		for _, e := range entries {
+			fmt.Fprintf(&buf, "- field: %s\n  name: %s\n  value: %s\n  source: %s\n", e.Field, e.Name, strconv.Quote(e.Value), e.Source)
+			if e.Redacted {
+				buf.WriteString("  redacted: true\n")
+			}
+		}
+	default:
+		return nil, errors.New("unsupported format")
+	}
+
+	return buf.Bytes(), nil
+}
+
+// provenance names what supplied the value of f. A set value that no source
+// and no default tag explain must have been preset before ReadENV ran.
+func (d *decoder) provenance(f field, set bool) string {
+	if val, s := d.source(f.Name); val != "" {
+		return sourceName(s)
+	}
+
+	if val, s := d.source(f.Name + fileSuffix); val != "" {
+		return sourceName(s) + " " + f.Name + fileSuffix
+	}
+
+	for _, a := range f.Aliases {
+		if val, s := d.source(a); val != "" {
+			return sourceName(s) + " " + a
+		}
+
+		if val, s := d.source(a + fileSuffix); val != "" {
+			return sourceName(s) + " " + a + fileSuffix
+		}
+	}
+
+	if f.Default != "" {
+		return "default"
+	}
+
+	if set {
+		return "preset"
+	}
+
+	return "unset"
+}
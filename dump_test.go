@@ -0,0 +1,140 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+type ConfigDump struct {
+	Host     string `env:"HOST" default:"localhost"`
+	Port     int    `env:"PORT" default:"8080"`
+	Password string `env:"PASSWORD" secret:"true"`
+	Key      Secret `env:"KEY"`
+	Token    string `env:"TOKEN"`
+	DB       struct {
+		Name string `env:"NAME"`
+	} `env:"DB"`
+}
+
+func TestDump(t *testing.T) {
+	key := filepath.Join(t.TempDir(), "key")
+	if err := os.WriteFile(key, []byte("k3y"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	src := MapSource{"PORT": "9090", "PASSWORD": "hunter2", "KEY_FILE": key, "DB_NAME": "app"}
+	c := &ConfigDump{}
+
+	if err := ReadENV(c, WithSources(src)); err != nil {
+		t.Error(err)
+	}
+
+	data, err := Dump(c, FormatJSON, WithSources(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var entries []dumpEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []dumpEntry{
+		{Field: "Host", Name: "HOST", Value: "localhost", Source: "default"},
+		{Field: "Port", Name: "PORT", Value: "9090", Source: "map"},
+		{Field: "Password", Name: "PASSWORD", Value: redacted, Source: "map", Redacted: true},
+		{Field: "Key", Name: "KEY", Value: redacted, Source: "map KEY_FILE", Redacted: true},
+		{Field: "Token", Name: "TOKEN", Value: "", Source: "unset"},
+		{Field: "DB.Name", Name: "DB_NAME", Value: "app", Source: "map"},
+	}
+
+	if len(entries) != len(want) {
+		t.Fatal("dump entries", entries)
+	}
+
+	for i := range want {
+		if entries[i] != want[i] {
+			t.Error("dump entry", entries[i])
+		}
+	}
+
+	for _, format := range []Format{FormatText, FormatMarkdown, FormatYAML} {
+		data, err := Dump(c, format, WithSources(src))
+		if err != nil {
+			t.Error(err)
+		}
+
+		if s := string(data); strings.Contains(s, "hunter2") || strings.Contains(s, "k3y") || !strings.Contains(s, "DB_NAME") {
+			t.Error("dump format", format, s)
+		}
+	}
+
+	data, err = Dump(c, FormatYAML, WithSources(src))
+	if err != nil {
+		t.Error(err)
+	}
+
+	if !strings.Contains(string(data), "- field: Password\n  name: PASSWORD\n  value: \"[REDACTED]\"\n  source: map\n  redacted: true\n") {
+		t.Error("dump yaml", string(data))
+	}
+}
+
+func TestDumpPreset(t *testing.T) {
+	c := &ConfigDump{Token: "t0ken"}
+
+	if err := ReadENV(c, WithSources(MapSource{}), KeepPresets()); err != nil {
+		t.Error(err)
+	}
+
+	data, err := Dump(c, FormatJSON, WithSources(MapSource{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var entries []dumpEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(entries) != 6 || entries[4].Source != "preset" || entries[4].Value != "t0ken" || entries[5].Source != "unset" {
+		t.Error("dump preset", entries)
+	}
+}
+
+type DumpDuration struct{ time.Duration }
+
+func (d *DumpDuration) UnmarshalENV(data []byte) (err error) {
+	d.Duration, err = time.ParseDuration(string(data))
+	return err
+}
+
+func TestDumpUnmarshaler(t *testing.T) {
+	type config struct {
+		Timeout DumpDuration `env:"TIMEOUT" default:"1s"`
+		Secret  DumpDuration `env:"SECRET" secret:"true"`
+	}
+
+	src := MapSource{"SECRET": "2m"}
+	c := &config{}
+	if err := ReadENV(c, WithSources(src)); err != nil {
+		t.Error(err)
+	}
+
+	data, err := Dump(c, FormatJSON, WithSources(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var entries []dumpEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(entries) != 2 || entries[0].Value != "1s" || entries[1].Value != redacted || strings.Contains(string(data), "2m") {
+		t.Error("dump unmarshaler", entries)
+	}
+}
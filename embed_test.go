@@ -0,0 +1,111 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type EmbedBase struct {
+	Host string `env:"HOST" default:"localhost"`
+	Port int    `env:"PORT"`
+}
+
+type EmbedTLS struct {
+	Cert string `env:"CERT"`
+}
+
+type ConfigEmbed struct {
+	EmbedBase
+	*EmbedTLS `env:"TLS"`
+	Admin     EmbedBase `env:"ADMIN,inline"`
+	Metrics   EmbedBase `env:",squash"`
+}
+
+type ConfigEmbedSquash struct {
+	Name      string `env:"NAME"`
+	EmbedBase `env:",squash"`
+}
+
+type ConfigEmbedConflict struct {
+	EmbedBase
+	Host string `env:"HOST"`
+}
+
+type ConfigEmbedPtrConflict struct {
+	Port       int `env:"DB_PORT"`
+	*EmbedBase `env:"DB"`
+}
+
+type ConfigEmbedBadTag struct {
+	Host string `env:"HOST,inline"`
+}
+
+func TestEmbedded(t *testing.T) {
+	c := &ConfigEmbed{}
+	err := ReadENV(c, WithSources(MapSource{"PORT": "80", "TLS_CERT": "cert", "ADMIN_HOST": "admin"}))
+
+	if err == nil || err.Error() != "fields EmbedBase.Host and Metrics.Host both read HOST" {
+		t.Error("squash conflict", err)
+	}
+
+	s := &ConfigEmbedSquash{}
+	if err := ReadENV(s, WithSources(MapSource{"NAME": "svc", "PORT": "80"})); err != nil {
+		t.Error(err)
+	}
+
+	if s.Name != "svc" || s.Host != "localhost" || s.Port != 80 {
+		t.Error("squash", s)
+	}
+}
+
+func TestEmbeddedPrefix(t *testing.T) {
+	type config struct {
+		EmbedBase
+		*EmbedTLS `env:"TLS"`
+		Admin     EmbedBase `env:"ADMIN,inline"`
+	}
+
+	c := &config{}
+	if err := ReadENV(c, WithSources(MapSource{"PORT": "80", "TLS_CERT": "cert", "ADMIN_HOST": "admin"})); err != nil {
+		t.Error(err)
+	}
+
+	if c.Host != "localhost" || c.Port != 80 {
+		t.Error("embedded parent prefix", c.EmbedBase)
+	}
+
+	if c.EmbedTLS == nil || c.Cert != "cert" {
+		t.Error("embedded pointer", c.EmbedTLS)
+	}
+
+	if c.Admin.Host != "admin" {
+		t.Error("inline prefix", c.Admin)
+	}
+
+	names := []string{}
+	for _, f := range fields(reflect.TypeOf(c)) {
+		names = append(names, f.Name)
+	}
+
+	if strings.Join(names, ",") != "HOST,PORT,TLS_CERT,ADMIN_HOST,ADMIN_PORT" {
+		t.Error("embedded names", names)
+	}
+}
+
+func TestEmbeddedConflict(t *testing.T) {
+	err := ReadENV(&ConfigEmbedConflict{}, WithSources(MapSource{}))
+	if err == nil || err.Error() != "fields EmbedBase.Host and Host both read HOST" {
+		t.Error("embedded conflict", err)
+	}
+
+	err = ReadENV(&ConfigEmbedPtrConflict{}, WithSources(MapSource{}))
+	if err == nil || err.Error() != "fields Port and EmbedBase.Port both read DB_PORT" {
+		t.Error("embedded pointer conflict", err)
+	}
+
+	err = ReadENV(&ConfigEmbedBadTag{}, WithSources(MapSource{}))
+	if err == nil || err.Error() != "Host (HOST): env tag options squash and inline apply to struct fields only" {
+		t.Error("leaf tag option", err)
+	}
+}
@@ -0,0 +1,47 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var ErrRequired = errors.New("required variable is not set")
+
+type DecodeError struct {
+	Field string
+	Var   string
+	Err   error
+}
+
+func (e *DecodeError) Error() string {
+	if e.Field == "" {
+		return e.Err.Error()
+	}
+
+	if e.Var == "" {
+		return fmt.Sprintf("%s: %v", e.Field, e.Err)
+	}
+
+	return fmt.Sprintf("%s (%s): %v", e.Field, e.Var, e.Err)
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// Errors collects every validation failure found while decoding.
+type Errors []error
+
+func (e Errors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+
+	return strings.Join(msgs, "; ")
+}
+
+func (e Errors) Unwrap() []error {
+	return e
+}
@@ -0,0 +1,128 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// resolve returns the value of tag from the sources, with references
+// expanded when enabled. Contents of <tag>_FILE files are kept literal.
+// chain holds the variables being resolved.
+func (d *decoder) resolve(tag string, chain []string) (string, error) {
+	if tag == "" {
+		return "", nil
+	}
+
+	for _, c := range chain {
+		if c == tag {
+			return "", fmt.Errorf("reference cycle %s -> %s", strings.Join(chain, " -> "), tag)
+		}
+	}
+
+	val, literal, err := d.getenv(tag)
+	if err != nil || val == "" || literal || !d.expand {
+		return val, err
+	}
+
+	return d.interpolate(val, append(chain[:len(chain):len(chain)], tag))
+}
+
+func (d *decoder) interpolate(val string, chain []string) (string, error) {
+	if !strings.Contains(val, "$") {
+		return val, nil
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(val); i++ {
+		if val[i] != '$' || i+1 == len(val) {
+			b.WriteByte(val[i])
+			continue
+		}
+
+		switch val[i+1] {
+		case '$':
+			b.WriteByte('$')
+			i++
+		case '{':
+			end := closingBrace(val, i+2)
+			if end < 0 {
+				return "", errors.New("unterminated reference")
+			}
+
+			ref, err := d.reference(val[i+2:end], chain)
+			if err != nil {
+				return "", err
+			}
+
+			b.WriteString(ref)
+			i = end
+		default:
+			b.WriteByte('$')
+		}
+	}
+
+	return b.String(), nil
+}
+
+// reference expands the body of a single ${...} reference.
+func (d *decoder) reference(ref string, chain []string) (string, error) {
+	name, word, op := ref, "", ""
+	if i := strings.Index(ref, ":"); i >= 0 {
+		name, word = ref[:i], ref[i+1:]
+		if word != "" {
+			op, word = word[:1], word[1:]
+		}
+
+		if op != "-" && op != "?" {
+			return "", fmt.Errorf("invalid reference ${%s}", ref)
+		}
+	}
+
+	if name == "" {
+		return "", errors.New("empty reference ${}")
+	}
+
+	val, err := d.resolve(name, chain)
+	if err != nil || val != "" {
+		return val, err
+	}
+
+	switch op {
+	case "-":
+		return d.interpolate(word, chain)
+	case "?":
+		if word == "" {
+			word = "not set"
+		}
+
+		msg, err := d.interpolate(word, chain)
+		if err != nil {
+			return "", err
+		}
+
+		return "", fmt.Errorf("%s: %s", name, msg)
+	}
+
+	return "", nil
+}
+
+func closingBrace(s string, i int) int {
+	depth := 0
+	for ; i < len(s); i++ {
+		switch {
+		case s[i] == '$' && i+1 < len(s) && s[i+1] == '$':
+			i++
+		case s[i] == '$' && i+1 < len(s) && s[i+1] == '{':
+			depth++
+			i++
+		case s[i] == '}':
+			if depth == 0 {
+				return i
+			}
+			depth--
+		}
+	}
+
+	return -1
+}
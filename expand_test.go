@@ -0,0 +1,99 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+type ConfigExpand struct {
+	DSN      string `env:"DSN" default:"postgres://${DB_USER}@${DB_HOST:-localhost}/${DB_NAME:-app}"`
+	Price    string `env:"PRICE" default:"$$5"`
+	Literal  string `env:"LITERAL"`
+	Nested   string `env:"NESTED"`
+	Required string `env:"REQUIRED" default:"${DB_PASSWORD:?password is required}"`
+}
+
+func TestExpand(t *testing.T) {
+	src := MapSource{
+		"DB_USER":     "admin",
+		"DB_PASSWORD": "secret",
+		"LITERAL":     "${DB_USER}",
+		"NESTED":      "${MISSING:-${DB_USER}-${DB_NAME:-none}}",
+	}
+
+	c := &ConfigExpand{}
+
+	if err := ReadENV(c, WithSources(src)); err != nil {
+		t.Error(err)
+	}
+
+	if c.Literal != "${DB_USER}" {
+		t.Error("expand disabled LITERAL")
+	}
+
+	c = &ConfigExpand{}
+
+	if err := ReadENV(c, WithSources(src), Expand()); err != nil {
+		t.Error(err)
+	}
+
+	if c.DSN != "postgres://admin@localhost/app" {
+		t.Error("expand default DSN", c.DSN)
+	}
+
+	if c.Price != "$5" {
+		t.Error("expand escape PRICE", c.Price)
+	}
+
+	if c.Literal != "admin" {
+		t.Error("expand env LITERAL", c.Literal)
+	}
+
+	if c.Nested != "admin-none" {
+		t.Error("expand env NESTED", c.Nested)
+	}
+
+	if c.Required != "secret" {
+		t.Error("expand default REQUIRED", c.Required)
+	}
+
+	delete(src, "DB_PASSWORD")
+
+	err := ReadENV(&ConfigExpand{}, WithSources(src), Expand())
+	if err == nil || !strings.Contains(err.Error(), "DB_PASSWORD: password is required") {
+		t.Error("expand error REQUIRED", err)
+	}
+
+	src["DB_PASSWORD"] = "x"
+	src["LITERAL"] = "${A}"
+	src["A"] = "${B}"
+	src["B"] = "${LITERAL}"
+
+	err = ReadENV(&ConfigExpand{}, WithSources(src), Expand())
+	if err == nil || !strings.Contains(err.Error(), "reference cycle LITERAL -> A -> B -> LITERAL") {
+		t.Error("expand cycle LITERAL", err)
+	}
+
+	src["LITERAL"] = "${A"
+
+	if err := ReadENV(&ConfigExpand{}, WithSources(src), Expand()); err == nil {
+		t.Error("expand unterminated LITERAL")
+	}
+}
+
+func TestExpandFileLiteral(t *testing.T) {
+	password := writeFile(t, "password", "pa$${x")
+
+	c := &ConfigExpand{}
+	if err := ReadENV(c, WithSources(MapSource{"LITERAL_FILE": password, "NESTED": "${LITERAL}", "DB_PASSWORD": "x"}), Expand()); err != nil {
+		t.Error(err)
+	}
+
+	if c.Literal != "pa$${x" {
+		t.Error("expand file contents", c.Literal)
+	}
+
+	if c.Nested != "pa$${x" {
+		t.Error("expand reference to file contents", c.Nested)
+	}
+}
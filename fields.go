@@ -0,0 +1,147 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+)
+
+const (
+	desName = "description"
+	reqName = "required"
+	dynName = "dynamic"
+)
+
+var unmarshalerType = reflect.TypeOf((*Unmarshaler)(nil)).Elem()
+
+// field describes a variable reachable from a config struct, resolved the
+// same way decodeStruct resolves it.
+type field struct {
+	Path        string
+	Name        string
+	Aliases     []string
+	Type        reflect.Type
+	Default     string
+	Description string
+	Required    bool
+	Secret      bool
+	Dynamic     bool
+	Index       []int
+	Tag         reflect.StructTag
+}
+
+func fields(t reflect.Type) []field {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	return appendFields(nil, t, "", "", nil, false, nil)
+}
+
+// appendFields lists the variables below t. seen holds the struct types
+// being walked; a recursive type is listed once, not at every depth.
+func appendFields(fs []field, t reflect.Type, tag, path string, index []int, dynamic bool, seen []reflect.Type) []field {
+	for _, s := range seen {
+		if s == t {
+			return fs
+		}
+	}
+	seen = append(seen[:len(seen):len(seen)], t)
+
+	p := planFor(t, tag)
+	for i := range p.fields {
+		f := &p.fields[i]
+		if f.err != nil {
+			continue
+		}
+
+		sPath := f.field.Name
+		if path != "" {
+			sPath = path + "." + f.field.Name
+		}
+		sIndex := append(index[:len(index):len(index)], f.index)
+		sDynamic := dynamic || f.dynamic
+
+		if !f.leaf {
+			elemType := f.field.Type
+			for elemType.Kind() == reflect.Ptr {
+				elemType = elemType.Elem()
+			}
+
+			fs = appendFields(fs, elemType, f.tag, sPath, sIndex, sDynamic, seen)
+			continue
+		}
+
+		fs = append(fs, field{
+			Path:        sPath,
+			Name:        f.tag,
+			Aliases:     f.aliases,
+			Type:        f.field.Type,
+			Default:     f.def,
+			Description: f.field.Tag.Get(desName),
+			Required:    f.required,
+			Secret:      f.secret,
+			Dynamic:     sDynamic,
+			Index:       sIndex,
+			Tag:         f.field.Tag,
+		})
+	}
+
+	return fs
+}
+
+// isLeaf reports whether the field is decoded from a single variable rather
+// than by walking its own fields.
+func isLeaf(field reflect.StructField) bool {
+	t := field.Type
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t.Kind() != reflect.Struct || field.Tag.Get(filName) == "true" {
+		return true
+	}
+
+	return reflect.PtrTo(t).Implements(unmarshalerType)
+}
+
+// fieldByIndex is like reflect.Value.FieldByIndex but reports false instead
+// of panicking on a nil embedded pointer.
+func fieldByIndex(v reflect.Value, index []int) (reflect.Value, bool) {
+	for _, i := range index {
+		for v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return v, false
+			}
+			v = v.Elem()
+		}
+		v = v.Field(i)
+	}
+
+	return v, true
+}
+
+// fieldByPath returns the field at a dotted Go field path such as "DB.Port".
+func fieldByPath(v reflect.Value, path string) (reflect.Value, bool) {
+	for _, name := range strings.Split(path, ".") {
+		for v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return v, false
+			}
+			v = v.Elem()
+		}
+
+		if v.Kind() != reflect.Struct {
+			return v, false
+		}
+
+		if v = v.FieldByName(name); !v.IsValid() {
+			return v, false
+		}
+	}
+
+	return v, true
+}
@@ -0,0 +1,41 @@
+package config
+
+import (
+	"os"
+	"strings"
+)
+
+// getenv returns the value of tag, falling back to the contents of the file
+// named by <tag>_FILE when tag itself is unset. literal reports a value read
+// from a file, which is never interpolated.
+func (d *decoder) getenv(tag string) (val string, literal bool, err error) {
+	if tag == "" {
+		return "", false, nil
+	}
+
+	if val := d.lookup(tag); val != "" {
+		return val, false, nil
+	}
+
+	if path := d.lookup(tag + fileSuffix); path != "" {
+		val, err := d.readFile(path)
+		return val, true, err
+	}
+
+	return "", false, nil
+}
+
+func (d *decoder) readFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	val := string(data)
+	if d.trimNewline {
+		val = strings.TrimSuffix(val, "\n")
+		val = strings.TrimSuffix(val, "\r")
+	}
+
+	return val, nil
+}
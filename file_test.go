@@ -0,0 +1,98 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type ConfigFile struct {
+	Password string `env:"FILE_PASSWORD" default:"default_password"`
+	Token    string `env:"FILE_TOKEN" file:"true"`
+	Port     int    `env:"FILE_PORT"`
+}
+
+func TestReadFile(t *testing.T) {
+	dir := t.TempDir()
+
+	password := filepath.Join(dir, "password")
+	if err := os.WriteFile(password, []byte("secret\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	token := filepath.Join(dir, "token")
+	if err := os.WriteFile(token, []byte("token\r\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	for k, v := range map[string]string{
+		"FILE_PASSWORD":      "",
+		"FILE_PASSWORD_FILE": "",
+		"FILE_TOKEN":         token,
+		"FILE_PORT":          "",
+		"FILE_PORT_FILE":     "",
+	} {
+		if err := os.Setenv(k, v); err != nil {
+			t.Error(err)
+		}
+	}
+
+	c := &ConfigFile{}
+
+	if err := ReadENV(c); err != nil {
+		t.Error(err)
+	}
+
+	if c.Password != "default_password" {
+		t.Error("default env FILE_PASSWORD")
+	}
+
+	if c.Token != "token\r\n" {
+		t.Error("read file FILE_TOKEN")
+	}
+
+	if err := os.Setenv("FILE_PASSWORD_FILE", password); err != nil {
+		t.Error(err)
+	}
+
+	c = &ConfigFile{}
+
+	if err := ReadENV(c, TrimNewline()); err != nil {
+		t.Error(err)
+	}
+
+	if c.Password != "secret" {
+		t.Error("read file FILE_PASSWORD_FILE")
+	}
+
+	if c.Token != "token" {
+		t.Error("read file FILE_TOKEN")
+	}
+
+	if err := os.Setenv("FILE_PASSWORD", "env_password"); err != nil {
+		t.Error(err)
+	}
+
+	c = &ConfigFile{}
+
+	if err := ReadENV(c); err != nil {
+		t.Error(err)
+	}
+
+	if c.Password != "env_password" {
+		t.Error("read env FILE_PASSWORD")
+	}
+
+	if err := os.Setenv("FILE_PORT_FILE", filepath.Join(dir, "missing")); err != nil {
+		t.Error(err)
+	}
+
+	var de *DecodeError
+	if err := ReadENV(&ConfigFile{}); !errors.As(err, &de) || de.Field != "Port" || de.Var != "FILE_PORT" || !errors.Is(err, os.ErrNotExist) {
+		t.Error("read file FILE_PORT_FILE error", err)
+	}
+
+	os.Unsetenv("FILE_PASSWORD")
+	os.Unsetenv("FILE_PORT_FILE")
+}
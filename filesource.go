@@ -0,0 +1,286 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+type ParseError struct {
+	File string
+	Line int
+	Err  error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("%s:%d: %v", e.File, e.Line, e.Err)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// FileSource serves the keys of a config file, or of a directory of files,
+// flattened into the same PARENT_CHILD names that nested structs are read
+// from.
+type FileSource struct {
+	path string
+	load func(path string) (map[string]string, error)
+
+	mu     sync.RWMutex
+	values map[string]string
+}
+
+// JSONFile reads a JSON object. Nested objects are flattened into
+// PARENT_CHILD keys, arrays are joined with commas, and an object of scalars
+// is also served under its own key in map form (k:v,...).
+func JSONFile(path string) (*FileSource, error) {
+	return newFileSource(path, parseJSON)
+}
+
+// INIFile reads key = value lines grouped in [section] headers, which become
+// key prefixes.
+func INIFile(path string) (*FileSource, error) {
+	return newFileSource(path, func(data []byte) (map[string]string, error) {
+		return parseINI(data, false)
+	})
+}
+
+// PropertiesFile reads key=value or key: value lines, including .env files.
+// Dotted keys become PARENT_CHILD keys.
+func PropertiesFile(path string) (*FileSource, error) {
+	return newFileSource(path, func(data []byte) (map[string]string, error) {
+		return parseINI(data, true)
+	})
+}
+
+func newFileSource(path string, parse func([]byte) (map[string]string, error)) (*FileSource, error) {
+	return newSource(path, func(path string) (map[string]string, error) {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		values, err := parse(data)
+		if err != nil {
+			var pe *ParseError
+			if errors.As(err, &pe) {
+				pe.File = path
+			}
+
+			return nil, err
+		}
+
+		return values, nil
+	})
+}
+
+func newSource(path string, load func(string) (map[string]string, error)) (*FileSource, error) {
+	s := &FileSource{path: path, load: load}
+	if err := s.Refresh(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+func (s *FileSource) Lookup(key string) (string, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	val, ok := s.values[key]
+	return val, ok
+}
+
+func (s *FileSource) Keys() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	keys := make([]string, 0, len(s.values))
+	for k := range s.values {
+		keys = append(keys, k)
+	}
+
+	return keys
+}
+
+func (s *FileSource) Path() string {
+	return s.path
+}
+
+func (s *FileSource) String() string {
+	return s.path
+}
+
+// Refresh reads the file or directory again. On error the previous values
+// are kept.
+func (s *FileSource) Refresh() error {
+	values, err := s.load(s.path)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.values = values
+	s.mu.Unlock()
+
+	return nil
+}
+
+func fileKey(prefix, key string) string {
+	key = strings.ToUpper(strings.NewReplacer(".", "_", "-", "_", " ", "_").Replace(strings.TrimSpace(key)))
+	return getTag(prefix, key)
+}
+
+func parseJSON(data []byte) (map[string]string, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var root map[string]interface{}
+	if err := dec.Decode(&root); err != nil {
+		line := 1
+		var se *json.SyntaxError
+		var te *json.UnmarshalTypeError
+		switch {
+		case errors.As(err, &se):
+			line += bytes.Count(data[:se.Offset], []byte("\n"))
+		case errors.As(err, &te):
+			line += bytes.Count(data[:te.Offset], []byte("\n"))
+		}
+
+		return nil, &ParseError{Line: line, Err: err}
+	}
+
+	m := map[string]string{}
+	flattenJSON(m, "", root)
+
+	return m, nil
+}
+
+func flattenJSON(m map[string]string, key string, v interface{}) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		kvs := make([]string, 0, len(v))
+		for k, child := range v {
+			flattenJSON(m, fileKey(key, k), child)
+			if s, ok := jsonScalar(child); ok && kvs != nil {
+				kvs = append(kvs, k+delimiterMap+s)
+			} else {
+				kvs = nil
+			}
+		}
+
+		if key != "" && len(kvs) > 0 {
+			sort.Strings(kvs)
+			m[key] = strings.Join(kvs, delimiterVal)
+		}
+	case []interface{}:
+		vals := make([]string, 0, len(v))
+		for i, child := range v {
+			if s, ok := jsonScalar(child); ok {
+				vals = append(vals, s)
+			} else {
+				flattenJSON(m, fmt.Sprintf("%s_%d", key, i), child)
+			}
+		}
+
+		if len(vals) == len(v) {
+			m[key] = strings.Join(vals, delimiterVal)
+		}
+	default:
+		if s, ok := jsonScalar(v); ok {
+			m[key] = s
+		}
+	}
+}
+
+func jsonScalar(v interface{}) (string, bool) {
+	switch v := v.(type) {
+	case string:
+		return v, true
+	case json.Number:
+		return v.String(), true
+	case bool:
+		return fmt.Sprint(v), true
+	}
+
+	return "", false
+}
+
+// parseINI reads INI files, or Java-style properties and .env files when
+// props is set.
+func parseINI(data []byte, props bool) (map[string]string, error) {
+	m := map[string]string{}
+	section := ""
+	lines := strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n")
+
+	for n := 0; n < len(lines); n++ {
+		lineNo := n + 1
+		line := strings.TrimSpace(lines[n])
+
+		for props && strings.HasSuffix(line, `\`) && n+1 < len(lines) {
+			n++
+			line = strings.TrimSuffix(line, `\`) + strings.TrimSpace(lines[n])
+		}
+
+		if line == "" || line[0] == '#' || line[0] == ';' || props && line[0] == '!' {
+			continue
+		}
+
+		if !props && line[0] == '[' {
+			if !strings.HasSuffix(line, "]") {
+				return nil, &ParseError{Line: lineNo, Err: errors.New("unterminated section header")}
+			}
+
+			section = fileKey("", line[1:len(line)-1])
+			continue
+		}
+
+		seps := "="
+		if props {
+			seps = "=:"
+			line = strings.TrimPrefix(line, "export ")
+		}
+
+		i := strings.IndexAny(line, seps)
+		if i <= 0 {
+			return nil, &ParseError{Line: lineNo, Err: fmt.Errorf("expected key%svalue", seps[:1])}
+		}
+
+		val, err := unquote(strings.TrimSpace(line[i+1:]))
+		if err != nil {
+			return nil, &ParseError{Line: lineNo, Err: err}
+		}
+
+		m[fileKey(section, line[:i])] = val
+	}
+
+	return m, nil
+}
+
+func unquote(s string) (string, error) {
+	if len(s) == 0 || s[0] != '"' && s[0] != '\'' {
+		return s, nil
+	}
+
+	if len(s) < 2 || s[len(s)-1] != s[0] {
+		return "", errors.New("unterminated quoted value")
+	}
+
+	if s[0] == '\'' {
+		return s[1 : len(s)-1], nil
+	}
+
+	val, err := strconv.Unquote(s)
+	if err != nil {
+		return "", errors.New("invalid quoted value")
+	}
+
+	return val, nil
+}
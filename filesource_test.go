@@ -0,0 +1,153 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type ConfigFileSource struct {
+	Name string `env:"NAME"`
+	DB   struct {
+		Host     string   `env:"HOST" default:"localhost"`
+		Port     int      `env:"PORT"`
+		Replicas []string `env:"REPLICAS"`
+	} `env:"DB"`
+	Labels map[string]string `env:"LABELS"`
+}
+
+// writeFile writes data to name, relative to a new temporary directory
+// unless it is absolute, and returns its path.
+func writeFile(t *testing.T, name, data string) string {
+	t.Helper()
+
+	path := name
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(t.TempDir(), name)
+	}
+
+	if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func TestFileSource(t *testing.T) {
+	jsonFile := writeFile(t, "config.json", `{
+	"name": "app",
+	"db": {"host": "db.local", "port": 5432, "replicas": ["r1", "r2"]},
+	"labels": {"team": "core", "tier": 1}
+}`)
+
+	iniFile := writeFile(t, "config.ini", `name = app
+; comment
+[db]
+host = "db.local"
+port = 5432
+replicas = r1,r2
+
+[labels]
+team = core
+`)
+
+	propsFile := writeFile(t, "config.properties", `# comment
+name: app
+db.host=db.local
+db.port = 5432
+db.replicas = r1,\
+  r2
+export LABELS='team:core,tier:1'
+`)
+
+	for path, load := range map[string]func(string) (*FileSource, error){
+		jsonFile:  JSONFile,
+		iniFile:   INIFile,
+		propsFile: PropertiesFile,
+	} {
+		src, err := load(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		c := &ConfigFileSource{}
+
+		if err := ReadENV(c, WithSources(src)); err != nil {
+			t.Error(err)
+		}
+
+		if c.Name != "app" || c.DB.Host != "db.local" || c.DB.Port != 5432 {
+			t.Error("file source", path, c)
+		}
+
+		if len(c.DB.Replicas) != 2 || c.DB.Replicas[1] != "r2" {
+			t.Error("file source DB_REPLICAS", path, c.DB.Replicas)
+		}
+
+		if path != iniFile && (c.Labels["team"] != "core" || c.Labels["tier"] != "1") {
+			t.Error("file source LABELS", path, c.Labels)
+		}
+	}
+}
+
+func TestFileSourceError(t *testing.T) {
+	for _, tc := range []struct {
+		load func(string) (*FileSource, error)
+		data string
+		line int
+	}{
+		{JSONFile, "{\n\"name\": \"app\",\n}", 3},
+		{INIFile, "name = app\n[db\n", 2},
+		{PropertiesFile, "name=app\n\nport\n", 3},
+		{PropertiesFile, "name=\"app\n", 1},
+	} {
+		path := writeFile(t, "config", tc.data)
+
+		_, err := tc.load(path)
+
+		var pe *ParseError
+		if !errors.As(err, &pe) || pe.File != path || pe.Line != tc.line {
+			t.Error("file source error", err)
+		}
+	}
+
+	if _, err := JSONFile(filepath.Join(t.TempDir(), "missing.json")); !errors.Is(err, os.ErrNotExist) {
+		t.Error("file source missing", err)
+	}
+}
+
+func TestFileSourceWatcher(t *testing.T) {
+	path := writeFile(t, "config.json", `{"name": "one"}`)
+
+	src, err := JSONFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w, err := NewWatcher[ConfigFileSource](WithSources(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	if err := os.WriteFile(path, []byte(`{"name": "two"}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := w.Reload(); err != nil {
+		t.Error(err)
+	}
+
+	if w.Load().Name != "two" {
+		t.Error("file source reload")
+	}
+
+	if err := os.WriteFile(path, []byte(`{"name": `), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := w.Reload(); err == nil || w.Load().Name != "two" {
+		t.Error("file source reload error")
+	}
+}
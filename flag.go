@@ -0,0 +1,123 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// FlagSource serves the flags registered by BindFlags that were set on the
+// command line.
+type FlagSource struct {
+	values map[string]*flagValue
+}
+
+func (s *FlagSource) Lookup(key string) (string, bool) {
+	v, ok := s.values[key]
+	if !ok || !v.set {
+		return "", false
+	}
+
+	return v.val, true
+}
+
+func (s *FlagSource) Keys() []string {
+	var keys []string
+	for key, v := range s.values {
+		if v.set {
+			keys = append(keys, key)
+		}
+	}
+
+	return keys
+}
+
+func (s *FlagSource) String() string {
+	return "flags"
+}
+
+// BindFlags registers a flag for every variable read by ReadENV for i, named
+// after the variable: DB_HOST becomes -db-host. Pass the returned source to
+// WithFlags after fs.Parse so that flags take priority.
+func BindFlags(fs *flag.FlagSet, i interface{}) (*FlagSource, error) {
+	s := &FlagSource{values: map[string]*flagValue{}}
+	for _, f := range fields(reflect.TypeOf(i)) {
+		if f.Name == "" {
+			continue
+		}
+
+		name := flagName(f.Name)
+		if fs.Lookup(name) != nil {
+			return nil, fmt.Errorf("%s (%s): flag -%s already defined", f.Path, f.Name, name)
+		}
+
+		v := &flagValue{typ: f.Type, def: f.Default}
+		if f.Secret && v.def != "" {
+			v.def = redacted
+		}
+
+		s.values[f.Name] = v
+		fs.Var(v, name, f.Description)
+	}
+
+	return s, nil
+}
+
+// WithFlags puts src in front of the other sources, the environment unless
+// WithSources replaces it, so that flags set on the command line win.
+func WithFlags(src *FlagSource) Option {
+	return func(o *options) {
+		o.flags = src
+	}
+}
+
+func flagName(name string) string {
+	return strings.ToLower(strings.ReplaceAll(name, "_", "-"))
+}
+
+type flagValue struct {
+	typ reflect.Type
+	def string
+	val string
+	set bool
+}
+
+func (v *flagValue) String() string {
+	if v.set {
+		return v.val
+	}
+
+	return v.def
+}
+
+// Set checks that s decodes as the field's type. Repeating a slice or map
+// flag appends to it.
+func (v *flagValue) Set(s string) error {
+	d := newDecoder(nil)
+	if err := d.decode(reflect.New(v.typ).Elem(), "", s); err != nil {
+		return err
+	}
+
+	if k := v.kind(); v.set && (k == reflect.Slice || k == reflect.Map) {
+		v.val += delimiterVal + s
+	} else {
+		v.val = s
+	}
+	v.set = true
+
+	return nil
+}
+
+func (v *flagValue) IsBoolFlag() bool {
+	return v.kind() == reflect.Bool
+}
+
+func (v *flagValue) kind() reflect.Kind {
+	t := v.typ
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	return t.Kind()
+}
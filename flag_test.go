@@ -0,0 +1,115 @@
+package config
+
+import (
+	"bytes"
+	"flag"
+	"strings"
+	"testing"
+	"time"
+)
+
+type ConfigFlag struct {
+	DB struct {
+		Host string `env:"HOST" default:"localhost" description:"database host"`
+		Port int    `env:"PORT" default:"5432"`
+	} `env:"DB"`
+	Debug    bool           `env:"DEBUG"`
+	Hosts    []string       `env:"HOSTS" default:"a"`
+	Labels   map[string]int `env:"LABELS"`
+	Timeout  *Time          `env:"TIMEOUT" default:"1s"`
+	Password string         `env:"PASSWORD" default:"hunter2" secret:"true"`
+}
+
+func TestBindFlags(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	var out bytes.Buffer
+	fs.SetOutput(&out)
+
+	src, err := BindFlags(fs, &ConfigFlag{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if f := fs.Lookup("db-host"); f == nil || f.Usage != "database host" || f.DefValue != "localhost" {
+		t.Error("flag db-host", f)
+	}
+
+	fs.PrintDefaults()
+	if strings.Contains(out.String(), "hunter2") {
+		t.Error("flag default PASSWORD")
+	}
+
+	args := []string{"-db-port", "6543", "-debug", "-hosts", "b,c", "-hosts", "d", "-labels", "x:1", "-timeout", "2s"}
+	if err := fs.Parse(args); err != nil {
+		t.Fatal(err)
+	}
+
+	c := &ConfigFlag{}
+
+	if err := ReadENV(c, WithFlags(src), WithSources(MapSource{"DB_HOST": "db", "DB_PORT": "1"})); err != nil {
+		t.Error(err)
+	}
+
+	if c.DB.Host != "db" || c.DB.Port != 6543 {
+		t.Error("flag priority DB", c.DB)
+	}
+
+	if !c.Debug {
+		t.Error("flag bool DEBUG")
+	}
+
+	if len(c.Hosts) != 3 || c.Hosts[2] != "d" {
+		t.Error("flag slice HOSTS", c.Hosts)
+	}
+
+	if c.Labels["x"] != 1 {
+		t.Error("flag map LABELS")
+	}
+
+	if c.Timeout.Duration != 2*time.Second {
+		t.Error("flag unmarshaler TIMEOUT")
+	}
+
+	fs = flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(&out)
+
+	if _, err := BindFlags(fs, &ConfigFlag{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := fs.Parse([]string{"-db-port", "port"}); err == nil {
+		t.Error("flag invalid DB_PORT")
+	}
+
+	if err := fs.Parse([]string{"-labels", "x:y"}); err == nil {
+		t.Error("flag invalid LABELS")
+	}
+
+	if _, err := BindFlags(fs, &ConfigFlag{}); err == nil {
+		t.Error("flag redefined")
+	}
+}
+
+func TestWithFlags(t *testing.T) {
+	t.Setenv("DB_HOST", "env.local")
+	t.Setenv("DB_PORT", "1")
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	src, err := BindFlags(fs, &ConfigFlag{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := fs.Parse([]string{"-db-port", "6543"}); err != nil {
+		t.Fatal(err)
+	}
+
+	c := &ConfigFlag{}
+	if err := ReadENV(c, WithFlags(src)); err != nil {
+		t.Error(err)
+	}
+
+	if c.DB.Host != "env.local" || c.DB.Port != 6543 {
+		t.Error("flags over env", c.DB)
+	}
+}
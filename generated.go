@@ -0,0 +1,80 @@
+package config
+
+import (
+	"os"
+	"reflect"
+)
+
+// SourceDecoder is implemented by the DecodeENV methods that cmd/envgen
+// writes. ReadENV calls it instead of walking the struct by reflection,
+// unless an option that changes how fields are filled in is given.
+type SourceDecoder interface {
+	DecodeENV(src Source) error
+}
+
+// resolver hands the decoder's sources to a generated decoder, with
+// <NAME>_FILE fallbacks and interpolation applied.
+type resolver struct {
+	d *decoder
+}
+
+func (r resolver) Lookup(key string) (string, bool) {
+	val, err := r.d.resolve(key, nil)
+	return val, err == nil && val != ""
+}
+
+func (r resolver) String() string {
+	return "config"
+}
+
+// Value returns the value of key in src, or defaultVal when it is unset.
+// It is used by generated decoders.
+func Value(src Source, key, defaultVal string) (string, error) {
+	if r, ok := src.(resolver); ok {
+		return r.d.value(key, defaultVal)
+	}
+
+	if val, _ := src.Lookup(key); val != "" {
+		return val, nil
+	}
+
+	return defaultVal, nil
+}
+
+// ReadFile returns the contents of the file behind a `file:"true"` field.
+// It is used by generated decoders.
+func ReadFile(src Source, path string) (string, error) {
+	if r, ok := src.(resolver); ok {
+		return r.d.readFile(path)
+	}
+
+	data, err := os.ReadFile(path)
+	return string(data), err
+}
+
+// Redact drops the offending value from a parse error of a secret field.
+// It is used by generated decoders.
+func Redact(err error) error {
+	return redactError(err)
+}
+
+// generated reports whether the options leave decoding as a generated
+// decoder does it.
+func (d *decoder) generated() bool {
+	return !d.presets && !d.presetsFirst && !d.optionalPtrs && !d.merge && !d.appendSlices && !d.mergeMaps
+}
+
+func (d *decoder) decodeGenerated(i interface{}) (bool, error) {
+	g, ok := i.(SourceDecoder)
+	if v := reflect.ValueOf(i); !ok || !d.generated() || v.Kind() == reflect.Ptr && v.IsNil() {
+		return false, nil
+	}
+
+	err := g.DecodeENV(resolver{d: d})
+	if errs, ok := err.(Errors); ok {
+		d.errs = append(d.errs, errs...)
+		return true, nil
+	}
+
+	return true, err
+}
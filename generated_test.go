@@ -0,0 +1,53 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type ConfigGenerated struct {
+	Host  string `env:"HOST" default:"localhost"`
+	calls int
+}
+
+func (c *ConfigGenerated) DecodeENV(src Source) error {
+	c.calls++
+
+	v, err := Value(src, "HOST", "localhost")
+	if err != nil {
+		return err
+	}
+	c.Host = v
+
+	return nil
+}
+
+func TestGenerated(t *testing.T) {
+	host := filepath.Join(t.TempDir(), "host")
+	if err := os.WriteFile(host, []byte("example.com\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	c := &ConfigGenerated{}
+
+	if err := ReadENV(c, WithSources(MapSource{"HOST_FILE": host}), TrimNewline()); err != nil {
+		t.Error(err)
+	}
+
+	if c.calls != 1 {
+		t.Error("generated decoder not used")
+	}
+
+	if c.Host != "example.com" {
+		t.Error("generated decoder HOST_FILE")
+	}
+
+	if err := Merge(c, WithSources(MapSource{"HOST": "merged"})); err != nil {
+		t.Error(err)
+	}
+
+	if c.calls != 1 || c.Host != "merged" {
+		t.Error("merge uses generated decoder")
+	}
+}
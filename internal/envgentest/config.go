@@ -0,0 +1,96 @@
+// Package envgentest holds config types with generated decoders, used to
+// check them against ReadENV.
+package envgentest
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/GMTror/config"
+)
+
+//go:generate go run github.com/GMTror/config/cmd/envgen -type Config
+
+type Level int
+
+func (l *Level) UnmarshalENV(data []byte) error {
+	switch strings.ToLower(string(data)) {
+	case "", "info":
+		*l = 0
+	case "debug":
+		*l = -1
+	case "warn":
+		*l = 1
+	default:
+		return errors.New("unknown level " + string(data))
+	}
+
+	return nil
+}
+
+type Mode string
+
+type Tags []string
+
+type Config struct {
+	Host    string               `env:"HOST" default:"localhost" description:"listen address"`
+	Port    int                  `env:"PORT" default:"8080"`
+	Debug   bool                 `env:"DEBUG"`
+	Ratio   float64              `env:"RATIO"`
+	Small   int8                 `env:"SMALL"`
+	Count   uint                 `env:"COUNT"`
+	Big     uint64               `env:"BIG"`
+	Mode    Mode                 `env:"MODE" default:"dev"`
+	Level   Level                `env:"LEVEL" default:"info"`
+	Levels  []Level              `env:"LEVELS"`
+	Tags    Tags                 `env:"TAGS"`
+	Ports   []int                `env:"PORTS"`
+	Limits  map[string]int       `env:"LIMITS"`
+	Labels  map[string]string    `env:"LABELS" default:"team:core"`
+	Timeout *int                 `env:"TIMEOUT"`
+	Token   config.Secret        `env:"TOKEN"`
+	Key     config.SecretOf[int] `env:"KEY"`
+	PIN     int                  `env:"PIN" secret:"true"`
+	CA      string               `env:"CA" file:"true"`
+	Name    string               `env:"NAME" required:"true"`
+	Ignored string               `env:"-"`
+	DB      DB                   `env:"DB"`
+	Cache   *Cache               `env:"CACHE"`
+	Meta    struct {
+		Owner string `env:"OWNER"`
+	} `env:"META"`
+	Embedded
+}
+
+type DB struct {
+	Host string `env:"HOST" default:"db"`
+	Port uint16 `env:"PORT" default:"5432"`
+	Pool Pool   `env:"POOL"`
+}
+
+func (db DB) Validate() error {
+	if db.Host == "invalid" {
+		return errors.New("invalid host")
+	}
+
+	return nil
+}
+
+type Pool struct {
+	Size  int      `env:"SIZE"`
+	Hosts []string `env:"HOSTS"`
+}
+
+func (p *Pool) SetDefaults() {
+	p.Size = 10
+	p.Hosts = []string{"a", "b"}
+}
+
+type Cache struct {
+	TTL   int    `env:"TTL" default:"60"`
+	Level *Level `env:"LEVEL"`
+}
+
+type Embedded struct {
+	Region string `env:"REGION" default:"eu"`
+}
@@ -0,0 +1,403 @@
+// Code generated by "envgen -type Config"; DO NOT EDIT.
+
+package envgentest
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/GMTror/config"
+)
+
+// DecodeENV decodes the environment into c without reflection.
+func (c *Config) DecodeENV(src config.Source) error {
+	var errs config.Errors
+	{
+		v, err := config.Value(src, "HOST", "localhost")
+		if err != nil {
+			return &config.DecodeError{Field: "Host", Var: "HOST", Err: err}
+		}
+		c.Host = v
+	}
+	{
+		v, err := config.Value(src, "PORT", "8080")
+		if err != nil {
+			return &config.DecodeError{Field: "Port", Var: "PORT", Err: err}
+		}
+		if v == "" {
+			c.Port = 0
+		} else {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				return &config.DecodeError{Field: "Port", Var: "PORT", Err: err}
+			}
+			c.Port = int(n)
+		}
+	}
+	{
+		v, err := config.Value(src, "DEBUG", "")
+		if err != nil {
+			return &config.DecodeError{Field: "Debug", Var: "DEBUG", Err: err}
+		}
+		if v == "" {
+			c.Debug = false
+		} else {
+			n, err := strconv.ParseBool(v)
+			if err != nil {
+				return &config.DecodeError{Field: "Debug", Var: "DEBUG", Err: err}
+			}
+			c.Debug = bool(n)
+		}
+	}
+	{
+		v, err := config.Value(src, "RATIO", "")
+		if err != nil {
+			return &config.DecodeError{Field: "Ratio", Var: "RATIO", Err: err}
+		}
+		if v == "" {
+			c.Ratio = 0
+		} else {
+			n, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				return &config.DecodeError{Field: "Ratio", Var: "RATIO", Err: err}
+			}
+			c.Ratio = float64(n)
+		}
+	}
+	{
+		v, err := config.Value(src, "SMALL", "")
+		if err != nil {
+			return &config.DecodeError{Field: "Small", Var: "SMALL", Err: err}
+		}
+		if v == "" {
+			c.Small = 0
+		} else {
+			n, err := strconv.ParseInt(v, 10, 8)
+			if err != nil {
+				return &config.DecodeError{Field: "Small", Var: "SMALL", Err: err}
+			}
+			c.Small = int8(n)
+		}
+	}
+	{
+		v, err := config.Value(src, "COUNT", "")
+		if err != nil {
+			return &config.DecodeError{Field: "Count", Var: "COUNT", Err: err}
+		}
+		if v == "" {
+			c.Count = 0
+		} else {
+			n, err := strconv.ParseUint(v, 10, 32)
+			if err != nil {
+				return &config.DecodeError{Field: "Count", Var: "COUNT", Err: err}
+			}
+			c.Count = uint(n)
+		}
+	}
+	{
+		v, err := config.Value(src, "BIG", "")
+		if err != nil {
+			return &config.DecodeError{Field: "Big", Var: "BIG", Err: err}
+		}
+		if v == "" {
+			c.Big = 0
+		} else {
+			n, err := strconv.ParseUint(v, 10, 64)
+			if err != nil {
+				return &config.DecodeError{Field: "Big", Var: "BIG", Err: err}
+			}
+			c.Big = uint64(n)
+		}
+	}
+	{
+		v, err := config.Value(src, "MODE", "dev")
+		if err != nil {
+			return &config.DecodeError{Field: "Mode", Var: "MODE", Err: err}
+		}
+		c.Mode = Mode(v)
+	}
+	{
+		v, err := config.Value(src, "LEVEL", "info")
+		if err != nil {
+			return &config.DecodeError{Field: "Level", Var: "LEVEL", Err: err}
+		}
+		if err := c.Level.UnmarshalENV([]byte(v)); err != nil {
+			return &config.DecodeError{Field: "Level", Var: "LEVEL", Err: err}
+		}
+	}
+	{
+		v, err := config.Value(src, "LEVELS", "")
+		if err != nil {
+			return &config.DecodeError{Field: "Levels", Var: "LEVELS", Err: err}
+		}
+		if v == "" {
+			c.Levels = nil
+		} else {
+			s0 := strings.Split(v, ",")
+			c.Levels = make([]Level, len(s0))
+			for i0, v := range s0 {
+				if err := c.Levels[i0].UnmarshalENV([]byte(v)); err != nil {
+					return &config.DecodeError{Field: "Levels", Var: "LEVELS", Err: err}
+				}
+			}
+		}
+	}
+	{
+		v, err := config.Value(src, "TAGS", "")
+		if err != nil {
+			return &config.DecodeError{Field: "Tags", Var: "TAGS", Err: err}
+		}
+		if v == "" {
+			c.Tags = nil
+		} else {
+			s0 := strings.Split(v, ",")
+			c.Tags = make(Tags, len(s0))
+			for i0, v := range s0 {
+				c.Tags[i0] = v
+			}
+		}
+	}
+	{
+		v, err := config.Value(src, "PORTS", "")
+		if err != nil {
+			return &config.DecodeError{Field: "Ports", Var: "PORTS", Err: err}
+		}
+		if v == "" {
+			c.Ports = nil
+		} else {
+			s0 := strings.Split(v, ",")
+			c.Ports = make([]int, len(s0))
+			for i0, v := range s0 {
+				if v == "" {
+					c.Ports[i0] = 0
+				} else {
+					n, err := strconv.Atoi(v)
+					if err != nil {
+						return &config.DecodeError{Field: "Ports", Var: "PORTS", Err: err}
+					}
+					c.Ports[i0] = int(n)
+				}
+			}
+		}
+	}
+	{
+		v, err := config.Value(src, "LIMITS", "")
+		if err != nil {
+			return &config.DecodeError{Field: "Limits", Var: "LIMITS", Err: err}
+		}
+		if v != "" {
+			m0 := make(map[string]int)
+			for _, kv := range strings.Split(v, ",") {
+				k, v, ok := strings.Cut(kv, ":")
+				if !ok {
+					return &config.DecodeError{Field: "Limits", Var: "LIMITS", Err: errors.New("missing \":\" in map entry")}
+				}
+				var e0 int
+				if v == "" {
+					e0 = 0
+				} else {
+					n, err := strconv.Atoi(v)
+					if err != nil {
+						return &config.DecodeError{Field: "Limits", Var: "LIMITS", Err: err}
+					}
+					e0 = int(n)
+				}
+				m0[k] = e0
+			}
+			c.Limits = m0
+		}
+	}
+	{
+		v, err := config.Value(src, "LABELS", "team:core")
+		if err != nil {
+			return &config.DecodeError{Field: "Labels", Var: "LABELS", Err: err}
+		}
+		if v != "" {
+			m0 := make(map[string]string)
+			for _, kv := range strings.Split(v, ",") {
+				k, v, ok := strings.Cut(kv, ":")
+				if !ok {
+					return &config.DecodeError{Field: "Labels", Var: "LABELS", Err: errors.New("missing \":\" in map entry")}
+				}
+				var e0 string
+				e0 = v
+				m0[k] = e0
+			}
+			c.Labels = m0
+		}
+	}
+	{
+		v, err := config.Value(src, "TIMEOUT", "")
+		if err != nil {
+			return &config.DecodeError{Field: "Timeout", Var: "TIMEOUT", Err: err}
+		}
+		if c.Timeout == nil {
+			c.Timeout = new(int)
+		}
+		if v == "" {
+			*c.Timeout = 0
+		} else {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				return &config.DecodeError{Field: "Timeout", Var: "TIMEOUT", Err: err}
+			}
+			*c.Timeout = int(n)
+		}
+	}
+	{
+		v, err := config.Value(src, "TOKEN", "")
+		if err != nil {
+			return &config.DecodeError{Field: "Token", Var: "TOKEN", Err: config.Redact(err)}
+		}
+		c.Token = config.Secret(v)
+	}
+	{
+		v, err := config.Value(src, "KEY", "")
+		if err != nil {
+			return &config.DecodeError{Field: "Key", Var: "KEY", Err: config.Redact(err)}
+		}
+		if err := c.Key.UnmarshalENV([]byte(v)); err != nil {
+			return &config.DecodeError{Field: "Key", Var: "KEY", Err: config.Redact(err)}
+		}
+	}
+	{
+		v, err := config.Value(src, "PIN", "")
+		if err != nil {
+			return &config.DecodeError{Field: "PIN", Var: "PIN", Err: config.Redact(err)}
+		}
+		if v == "" {
+			c.PIN = 0
+		} else {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				return &config.DecodeError{Field: "PIN", Var: "PIN", Err: config.Redact(err)}
+			}
+			c.PIN = int(n)
+		}
+	}
+	{
+		v, err := config.Value(src, "CA", "")
+		if err != nil {
+			return &config.DecodeError{Field: "CA", Var: "CA", Err: err}
+		}
+		if v != "" {
+			if v, err = config.ReadFile(src, v); err != nil {
+				return &config.DecodeError{Field: "CA", Var: "CA", Err: err}
+			}
+		}
+		c.CA = v
+	}
+	{
+		v, err := config.Value(src, "NAME", "")
+		if err != nil {
+			return &config.DecodeError{Field: "Name", Var: "NAME", Err: err}
+		}
+		if v == "" {
+			errs = append(errs, &config.DecodeError{Field: "Name", Var: "NAME", Err: config.ErrRequired})
+		}
+		c.Name = v
+	}
+	{
+		v, err := config.Value(src, "DB_HOST", "db")
+		if err != nil {
+			return &config.DecodeError{Field: "DB.Host", Var: "DB_HOST", Err: err}
+		}
+		c.DB.Host = v
+	}
+	{
+		v, err := config.Value(src, "DB_PORT", "5432")
+		if err != nil {
+			return &config.DecodeError{Field: "DB.Port", Var: "DB_PORT", Err: err}
+		}
+		if v == "" {
+			c.DB.Port = 0
+		} else {
+			n, err := strconv.ParseUint(v, 10, 16)
+			if err != nil {
+				return &config.DecodeError{Field: "DB.Port", Var: "DB_PORT", Err: err}
+			}
+			c.DB.Port = uint16(n)
+		}
+	}
+	c.DB.Pool.SetDefaults()
+	{
+		v, err := config.Value(src, "DB_POOL_SIZE", "")
+		if err != nil {
+			return &config.DecodeError{Field: "DB.Pool.Size", Var: "DB_POOL_SIZE", Err: err}
+		}
+		if v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				return &config.DecodeError{Field: "DB.Pool.Size", Var: "DB_POOL_SIZE", Err: err}
+			}
+			c.DB.Pool.Size = int(n)
+		}
+	}
+	{
+		v, err := config.Value(src, "DB_POOL_HOSTS", "")
+		if err != nil {
+			return &config.DecodeError{Field: "DB.Pool.Hosts", Var: "DB_POOL_HOSTS", Err: err}
+		}
+		if v != "" {
+			s0 := strings.Split(v, ",")
+			c.DB.Pool.Hosts = make([]string, len(s0))
+			for i0, v := range s0 {
+				if v != "" {
+					c.DB.Pool.Hosts[i0] = v
+				}
+			}
+		}
+	}
+	if err := c.DB.Validate(); err != nil {
+		errs = append(errs, &config.DecodeError{Field: "DB", Err: err})
+	}
+	if c.Cache == nil {
+		c.Cache = new(Cache)
+	}
+	{
+		v, err := config.Value(src, "CACHE_TTL", "60")
+		if err != nil {
+			return &config.DecodeError{Field: "Cache.TTL", Var: "CACHE_TTL", Err: err}
+		}
+		if v == "" {
+			c.Cache.TTL = 0
+		} else {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				return &config.DecodeError{Field: "Cache.TTL", Var: "CACHE_TTL", Err: err}
+			}
+			c.Cache.TTL = int(n)
+		}
+	}
+	{
+		v, err := config.Value(src, "CACHE_LEVEL", "")
+		if err != nil {
+			return &config.DecodeError{Field: "Cache.Level", Var: "CACHE_LEVEL", Err: err}
+		}
+		if c.Cache.Level == nil {
+			c.Cache.Level = new(Level)
+		}
+		if err := c.Cache.Level.UnmarshalENV([]byte(v)); err != nil {
+			return &config.DecodeError{Field: "Cache.Level", Var: "CACHE_LEVEL", Err: err}
+		}
+	}
+	{
+		v, err := config.Value(src, "META_OWNER", "")
+		if err != nil {
+			return &config.DecodeError{Field: "Meta.Owner", Var: "META_OWNER", Err: err}
+		}
+		c.Meta.Owner = v
+	}
+	{
+		v, err := config.Value(src, "REGION", "eu")
+		if err != nil {
+			return &config.DecodeError{Field: "Embedded.Region", Var: "REGION", Err: err}
+		}
+		c.Embedded.Region = v
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package envgentest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/GMTror/config"
+)
+
+// reflective has Config's fields but not its generated DecodeENV method.
+type reflective Config
+
+func TestGeneratedMatchesReflective(t *testing.T) {
+	dir := t.TempDir()
+	ca := filepath.Join(dir, "ca.pem")
+	if err := os.WriteFile(ca, []byte("certificate\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	token := filepath.Join(dir, "token")
+	if err := os.WriteFile(token, []byte("s3cret\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name string
+		env  config.MapSource
+		opts []config.Option
+	}{
+		{name: "defaults", env: config.MapSource{}},
+		{name: "set", env: config.MapSource{
+			"HOST": "example.com", "PORT": "9000", "DEBUG": "true", "RATIO": "0.5",
+			"SMALL": "-8", "COUNT": "7", "BIG": "18446744073709551615", "MODE": "prod",
+			"LEVEL": "debug", "LEVELS": "warn,,debug", "TAGS": "a,b", "PORTS": "1,,3",
+			"LIMITS": "cpu:2,mem:", "LABELS": "team:edge,tier:1", "TIMEOUT": "30",
+			"TOKEN": "t", "KEY": "42", "PIN": "1234", "CA": ca, "NAME": "svc",
+			"DB_HOST": "pg", "DB_PORT": "6432", "DB_POOL_SIZE": "3", "DB_POOL_HOSTS": "x,y,z",
+			"CACHE_TTL": "5", "CACHE_LEVEL": "warn", "META_OWNER": "ops", "REGION": "us",
+		}},
+		{name: "files", env: config.MapSource{"NAME": "svc", "TOKEN_FILE": token, "CA": ca}, opts: []config.Option{config.TrimNewline()}},
+		{name: "expand", env: config.MapSource{"NAME": "svc", "HOST": "${NAME}.local", "DB_HOST": "${HOST:-x}"}, opts: []config.Option{config.Expand()}},
+		{name: "required", env: config.MapSource{"PORT": "1"}},
+		{name: "validate", env: config.MapSource{"NAME": "svc", "DB_HOST": "invalid"}},
+		{name: "int", env: config.MapSource{"NAME": "svc", "PORT": "abc"}},
+		{name: "uint range", env: config.MapSource{"NAME": "svc", "COUNT": "4294967296"}},
+		{name: "unmarshaler", env: config.MapSource{"NAME": "svc", "LEVEL": "loud"}},
+		{name: "map entry", env: config.MapSource{"NAME": "svc", "LIMITS": "cpu"}},
+		{name: "secret", env: config.MapSource{"NAME": "svc", "PIN": "12x4"}},
+		{name: "secret unmarshaler", env: config.MapSource{"NAME": "svc", "KEY": "x"}},
+		{name: "missing file", env: config.MapSource{"NAME": "svc", "CA": filepath.Join(dir, "missing")}},
+		{name: "cycle", env: config.MapSource{"NAME": "${HOST}", "HOST": "${NAME}"}, opts: []config.Option{config.Expand()}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			opts := append([]config.Option{config.WithSources(c.env)}, c.opts...)
+
+			var gen Config
+			genErr := config.ReadENV(&gen, opts...)
+
+			var ref reflective
+			refErr := config.ReadENV(&ref, opts...)
+
+			if fmt.Sprint(genErr) != fmt.Sprint(refErr) {
+				t.Errorf("errors differ:\ngenerated:  %v\nreflective: %v", genErr, refErr)
+			}
+
+			if genErr == nil && !reflect.DeepEqual(gen, Config(ref)) {
+				t.Errorf("values differ:\ngenerated:  %+v\nreflective: %+v", gen, Config(ref))
+			}
+		})
+	}
+}
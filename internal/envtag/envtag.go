@@ -0,0 +1,50 @@
+// Package envtag parses env struct tags for the config package and the
+// tools that read config structs from source.
+package envtag
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Tag is a parsed env tag: a variable name followed by options.
+//
+// squash reads the fields of a struct with the parent's prefix, as an
+// untagged embedded struct does. inline adds the name to the prefix, as a
+// tagged struct field does; it only makes that default explicit.
+type Tag struct {
+	Name   string
+	Squash bool
+	Inline bool
+}
+
+func Parse(tag string) (Tag, error) {
+	name, opts, _ := strings.Cut(tag, ",")
+	t := Tag{Name: name}
+
+	for _, opt := range strings.Split(opts, ",") {
+		switch strings.TrimSpace(opt) {
+		case "":
+		case "squash":
+			t.Squash = true
+		case "inline":
+			t.Inline = true
+		default:
+			return t, fmt.Errorf("unknown env tag option %q", opt)
+		}
+	}
+
+	switch {
+	case t.Squash && t.Inline:
+		return t, fmt.Errorf("env tag options squash and inline are exclusive")
+	case t.Squash && t.Name != "":
+		return t, fmt.Errorf("env tag option squash does not take a name")
+	}
+
+	return t, nil
+}
+
+// Options reports whether the tag has squash or inline set.
+func (t Tag) Options() bool {
+	return t.Squash || t.Inline
+}
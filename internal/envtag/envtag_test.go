@@ -0,0 +1,25 @@
+package envtag
+
+import "testing"
+
+func TestParse(t *testing.T) {
+	for tag, want := range map[string]Tag{
+		"":            {},
+		"HOST":        {Name: "HOST"},
+		",squash":     {Squash: true},
+		"DB,inline":   {Name: "DB", Inline: true},
+		"DB, inline ": {Name: "DB", Inline: true},
+		"-":           {Name: "-"},
+	} {
+		got, err := Parse(tag)
+		if err != nil || got != want {
+			t.Errorf("Parse(%q) = %+v, %v", tag, got, err)
+		}
+	}
+
+	for _, tag := range []string{"DB,flatten", "DB,squash", ",squash,inline"} {
+		if _, err := Parse(tag); err == nil {
+			t.Errorf("Parse(%q) accepted", tag)
+		}
+	}
+}
@@ -0,0 +1,502 @@
+// Package gosrc reads config struct definitions from Go source, resolving
+// variable names the same way the config package does at run time.
+package gosrc
+
+import (
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/GMTror/config/internal/envtag"
+)
+
+const configPath = "github.com/GMTror/config"
+
+type Package struct {
+	Name  string
+	Path  string
+	Dir   string
+	Fset  *token.FileSet
+	Files []*ast.File
+
+	// Imports maps the names the files import packages under to their paths.
+	Imports map[string]string
+
+	types   map[string]*ast.TypeSpec
+	methods map[string]map[string]bool
+	deps    map[string]*Package
+}
+
+type Field struct {
+	Name     string
+	Path     string
+	Var      string
+	Tag      reflect.StructTag
+	Type     ast.Expr
+	Embedded bool
+	Children []*Field
+
+	// Recursive marks a struct already being walked; its Children are
+	// left empty, as config.Usage lists a recursive type once.
+	Recursive bool
+
+	// Pkg is the package the field is declared in.
+	Pkg *Package
+}
+
+func (f *Field) Leaf() bool {
+	return f.Children == nil
+}
+
+// Secret reports whether the field is tagged secret:"true" or holds one of
+// the config package's secret types.
+func (f *Field) Secret() bool {
+	if f.Tag.Get("secret") == "true" {
+		return true
+	}
+
+	t := f.Type
+	for {
+		switch tt := t.(type) {
+		case *ast.StarExpr:
+			t = tt.X
+			continue
+		case *ast.IndexExpr:
+			t = tt.X
+			continue
+		case *ast.SelectorExpr:
+			id, ok := tt.X.(*ast.Ident)
+			return ok && f.Pkg.Imports[id.Name] == configPath && (tt.Sel.Name == "Secret" || tt.Sel.Name == "SecretOf")
+		}
+
+		return false
+	}
+}
+
+// Import finds a package by directory or import path and loads it.
+func Import(path string) (*Package, error) {
+	if fi, err := os.Stat(path); err == nil && fi.IsDir() {
+		return Load(path)
+	}
+
+	bp, err := build.Import(path, ".", build.FindOnly)
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := Load(bp.Dir)
+	if err != nil {
+		return nil, err
+	}
+	p.Path = path
+
+	return p, nil
+}
+
+// Load parses the non-test Go files in dir.
+func Load(dir string) (*Package, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &Package{
+		Dir:     dir,
+		Fset:    token.NewFileSet(),
+		Imports: map[string]string{},
+		types:   map[string]*ast.TypeSpec{},
+		methods: map[string]map[string]bool{},
+		deps:    map[string]*Package{},
+	}
+
+	var names []string
+	for _, e := range entries {
+		name := e.Name()
+		if !e.IsDir() && strings.HasSuffix(name, ".go") && !strings.HasSuffix(name, "_test.go") {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	ctx := build.Default
+	for _, name := range names {
+		if ok, err := ctx.MatchFile(dir, name); err != nil || !ok {
+			continue
+		}
+
+		f, err := parser.ParseFile(p.Fset, filepath.Join(dir, name), nil, parser.ParseComments)
+		if err != nil {
+			return nil, err
+		}
+
+		if strings.HasSuffix(f.Name.Name, "_test") {
+			continue
+		}
+		if p.Name == "" {
+			p.Name = f.Name.Name
+		}
+		p.Files = append(p.Files, f)
+
+		for _, imp := range f.Imports {
+			path, _ := strconv.Unquote(imp.Path.Value)
+			name := path[strings.LastIndex(path, "/")+1:]
+			if imp.Name != nil {
+				name = imp.Name.Name
+			}
+			p.Imports[name] = path
+		}
+
+		for _, decl := range f.Decls {
+			switch decl := decl.(type) {
+			case *ast.GenDecl:
+				for _, spec := range decl.Specs {
+					if ts, ok := spec.(*ast.TypeSpec); ok {
+						p.types[ts.Name.Name] = ts
+					}
+				}
+			case *ast.FuncDecl:
+				if decl.Recv == nil || len(decl.Recv.List) == 0 {
+					continue
+				}
+
+				recv := typeName(decl.Recv.List[0].Type)
+				if p.methods[recv] == nil {
+					p.methods[recv] = map[string]bool{}
+				}
+				p.methods[recv][decl.Name.Name] = true
+			}
+		}
+	}
+
+	if len(p.Files) == 0 {
+		return nil, fmt.Errorf("no Go files in %s", dir)
+	}
+
+	return p, nil
+}
+
+// Type returns the declaration of a type defined in the package.
+func (p *Package) Type(name string) (*ast.TypeSpec, bool) {
+	ts, ok := p.types[name]
+	return ts, ok
+}
+
+func (p *Package) HasMethod(typeName, method string) bool {
+	return p.methods[typeName][method]
+}
+
+// Fields returns the field tree of the named struct type.
+func (p *Package) Fields(name string) ([]*Field, error) {
+	ts, ok := p.types[name]
+	if !ok {
+		return nil, fmt.Errorf("type %s not found in %s", name, p.Dir)
+	}
+
+	st, ok := ts.Type.(*ast.StructType)
+	if !ok {
+		return nil, fmt.Errorf("type %s is not a struct", name)
+	}
+
+	return p.fields(st, "", "", map[string]bool{p.Dir + "." + name: true})
+}
+
+func (p *Package) fields(st *ast.StructType, prefix, path string, seen map[string]bool) ([]*Field, error) {
+	fs := []*Field{}
+	for _, af := range st.Fields.List {
+		var tag reflect.StructTag
+		if af.Tag != nil {
+			s, err := strconv.Unquote(af.Tag.Value)
+			if err != nil {
+				return nil, err
+			}
+			tag = reflect.StructTag(s)
+		}
+
+		env, err := envtag.Parse(tag.Get("env"))
+		if err != nil {
+			return nil, err
+		}
+		if env.Name == "-" {
+			continue
+		}
+
+		names := af.Names
+		embedded := len(names) == 0
+		if embedded {
+			names = []*ast.Ident{ast.NewIdent(typeName(af.Type))}
+		}
+
+		for _, n := range names {
+			if !n.IsExported() && !p.promoted(af.Type, embedded) {
+				if _, ok := tag.Lookup("env"); ok {
+					return nil, fmt.Errorf("%s: unexported field %s cannot be set; export it or remove its env tag", join(path, ".", n.Name), n.Name)
+				}
+				continue
+			}
+
+			f := &Field{
+				Name:     n.Name,
+				Path:     join(path, ".", n.Name),
+				Var:      join(prefix, "_", env.Name),
+				Tag:      tag,
+				Type:     af.Type,
+				Embedded: embedded,
+				Pkg:      p,
+			}
+
+			if tag.Get("file") != "true" {
+				st, sp, name, err := p.structType(af.Type)
+				if err != nil {
+					return nil, fmt.Errorf("%s: %v", f.Path, err)
+				}
+
+				if st == nil && env.Options() {
+					return nil, fmt.Errorf("%s: env tag options squash and inline apply to struct fields only", f.Path)
+				}
+
+				if st != nil {
+					key := sp.Dir + "." + name
+					if seen[key] {
+						f.Children = []*Field{}
+						f.Recursive = true
+						fs = append(fs, f)
+						continue
+					}
+
+					next := seen
+					if name != "" {
+						next = map[string]bool{key: true}
+						for k := range seen {
+							next[k] = true
+						}
+					}
+
+					children, err := sp.fields(st, f.Var, f.Path, next)
+					if err != nil {
+						return nil, err
+					}
+					f.Children = children
+				}
+			}
+
+			fs = append(fs, f)
+		}
+	}
+
+	return fs, conflicts(fs)
+}
+
+// conflicts mirrors the check the config package makes when planning a
+// struct: a variable may not be read both by a field of an embedded or
+// squashed struct and by another field around it.
+func conflicts(fs []*Field) error {
+	type reader struct {
+		path     string
+		embedded bool
+	}
+
+	seen := map[string]reader{}
+	add := func(f *Field, embedded bool) error {
+		if f.Var == "" {
+			return nil
+		}
+
+		if r, ok := seen[f.Var]; ok && (r.embedded || embedded) {
+			return fmt.Errorf("fields %s and %s both read %s", r.path, f.Path, f.Var)
+		}
+		seen[f.Var] = reader{path: f.Path, embedded: embedded}
+
+		return nil
+	}
+
+	for _, f := range fs {
+		if f.Leaf() {
+			if err := add(f, false); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if env, _ := envtag.Parse(f.Tag.Get("env")); !f.Embedded && env.Name != "" {
+			continue
+		}
+
+		for _, l := range Leaves(f.Children) {
+			if err := add(l, true); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// structType returns the struct a field type decodes through and the
+// package declaring it, or nil if the field is read from a single variable.
+func (p *Package) structType(expr ast.Expr) (*ast.StructType, *Package, string, error) {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return p.structType(t.X)
+	case *ast.StructType:
+		return t, p, "", nil
+	case *ast.Ident:
+		ts, ok := p.types[t.Name]
+		if !ok || p.HasMethod(t.Name, "UnmarshalENV") {
+			return nil, nil, "", nil
+		}
+
+		switch u := ts.Type.(type) {
+		case *ast.StructType:
+			return u, p, t.Name, nil
+		case *ast.Ident, *ast.SelectorExpr, *ast.StarExpr:
+			if ts.Assign.IsValid() {
+				return p.structType(u)
+			}
+		}
+	case *ast.SelectorExpr:
+		id, ok := t.X.(*ast.Ident)
+		if !ok || p.Imports[id.Name] == "" || p.Imports[id.Name] == configPath {
+			return nil, nil, "", nil
+		}
+
+		dep, err := p.dep(p.Imports[id.Name])
+		if err != nil {
+			return nil, nil, "", err
+		}
+
+		return dep.structType(ast.NewIdent(t.Sel.Name))
+	}
+
+	return nil, nil, "", nil
+}
+
+// promoted reports whether an unexported field is an embedded struct whose
+// exported fields the config package sets, as it does not allocate
+// embedded pointers to unexported types.
+func (p *Package) promoted(expr ast.Expr, embedded bool) bool {
+	if _, ok := expr.(*ast.StarExpr); ok || !embedded {
+		return false
+	}
+
+	st, _, _, _ := p.structType(expr)
+	return st != nil
+}
+
+// dep loads an imported package.
+func (p *Package) dep(path string) (*Package, error) {
+	if dep, ok := p.deps[path]; ok {
+		return dep, nil
+	}
+
+	bp, err := build.Import(path, p.Dir, build.FindOnly)
+	if err != nil {
+		return nil, err
+	}
+
+	dep, err := Load(bp.Dir)
+	if err != nil {
+		return nil, err
+	}
+	dep.Path = path
+	p.deps[path] = dep
+
+	return dep, nil
+}
+
+// Leaves flattens a field tree into the fields read from a variable.
+func Leaves(fs []*Field) []*Field {
+	var leaves []*Field
+	for _, f := range fs {
+		if f.Leaf() {
+			leaves = append(leaves, f)
+		} else {
+			leaves = append(leaves, Leaves(f.Children)...)
+		}
+	}
+
+	return leaves
+}
+
+// TypeString formats a type expression the way reflect.Type.String does,
+// qualifying types declared in p with its package name.
+func (p *Package) TypeString(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		switch t.Name {
+		case "byte":
+			return "uint8"
+		case "rune":
+			return "int32"
+		case "any":
+			return "interface {}"
+		}
+
+		if _, ok := p.types[t.Name]; ok {
+			return p.Name + "." + t.Name
+		}
+		return t.Name
+	case *ast.StarExpr:
+		return "*" + p.TypeString(t.X)
+	case *ast.SelectorExpr:
+		if id, ok := t.X.(*ast.Ident); ok {
+			if path, ok := p.Imports[id.Name]; ok {
+				return path[strings.LastIndex(path, "/")+1:] + "." + t.Sel.Name
+			}
+		}
+		return p.TypeString(t.X) + "." + t.Sel.Name
+	case *ast.ArrayType:
+		if t.Len == nil {
+			return "[]" + p.TypeString(t.Elt)
+		}
+		if lit, ok := t.Len.(*ast.BasicLit); ok {
+			return "[" + lit.Value + "]" + p.TypeString(t.Elt)
+		}
+	case *ast.MapType:
+		return "map[" + p.TypeString(t.Key) + "]" + p.TypeString(t.Value)
+	case *ast.IndexExpr:
+		return p.TypeString(t.X) + "[" + p.TypeString(t.Index) + "]"
+	case *ast.StructType:
+		return "struct {...}"
+	case *ast.InterfaceType:
+		return "interface {...}"
+	}
+
+	return fmt.Sprintf("%T", expr)
+}
+
+func typeName(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		return t.Name
+	case *ast.StarExpr:
+		return typeName(t.X)
+	case *ast.SelectorExpr:
+		return t.Sel.Name
+	case *ast.IndexExpr:
+		return typeName(t.X)
+	case *ast.IndexListExpr:
+		return typeName(t.X)
+	}
+
+	return ""
+}
+
+// join mirrors getTag in the config package.
+func join(a, sep, b string) string {
+	switch {
+	case a == "":
+		return b
+	case b == "":
+		return a
+	}
+
+	return a + sep + b
+}
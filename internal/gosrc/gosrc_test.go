@@ -0,0 +1,106 @@
+package gosrc
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFields(t *testing.T) {
+	p, err := Load("testdata/app")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fs, err := p.Fields("Config")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names, paths []string
+	for _, f := range Leaves(fs) {
+		names = append(names, f.Var)
+		paths = append(paths, f.Path)
+	}
+
+	if !reflect.DeepEqual(names, []string{"REGION", "ADDR", "TLS_CERT", "CACHE_TTL", "CACHE_LEVEL"}) {
+		t.Error("names", names)
+	}
+
+	if !reflect.DeepEqual(paths, []string{"Embedded.Region", "Server.Addr", "Server.TLS.Cert", "Cache.TTL", "Cache.Level"}) {
+		t.Error("paths", paths)
+	}
+
+	if s := Leaves(fs)[4]; s.Pkg.TypeString(s.Type) != "*envgentest.Level" {
+		t.Error("type", s.Pkg.TypeString(s.Type))
+	}
+}
+
+func TestSquash(t *testing.T) {
+	p, err := Load("testdata/app")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fs, err := p.Fields("Flat")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for _, f := range Leaves(fs) {
+		names = append(names, f.Var)
+	}
+
+	if !reflect.DeepEqual(names, []string{"CERT", "SRV_ADDR", "SRV_TLS_CERT"}) {
+		t.Error("names", names)
+	}
+
+	if _, err := p.Fields("Clash"); err == nil || err.Error() != "fields Server.Addr and Addr both read ADDR" {
+		t.Error("conflict", err)
+	}
+}
+
+func TestUnexported(t *testing.T) {
+	p, err := Load("testdata/app")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fs, err := p.Fields("Hidden")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for _, f := range Leaves(fs) {
+		names = append(names, f.Path)
+	}
+
+	if !reflect.DeepEqual(names, []string{"base.Region", "TLS.Cert"}) {
+		t.Error("unexported", names)
+	}
+
+	if _, err := p.Fields("Tagged"); err == nil || err.Error() != "secret: unexported field secret cannot be set; export it or remove its env tag" {
+		t.Error("unexported tagged", err)
+	}
+}
+
+func TestRecursive(t *testing.T) {
+	p, err := Load("testdata/app")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fs, err := p.Fields("Node")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(fs) != 2 || !fs[1].Recursive || len(Leaves(fs)) != 1 {
+		t.Error("recursive type", fs)
+	}
+
+	if _, err := p.Fields("Missing"); err == nil {
+		t.Error("missing type")
+	}
+}
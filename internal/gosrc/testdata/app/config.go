@@ -0,0 +1,19 @@
+package app
+
+import "github.com/GMTror/config/internal/envgentest"
+
+type Config struct {
+	envgentest.Embedded
+	Server
+	Cache *envgentest.Cache `env:"CACHE"`
+}
+
+type Flat struct {
+	TLS TLS     `env:",squash"`
+	Srv *Server `env:"SRV,inline"`
+}
+
+type Clash struct {
+	Server
+	Addr string `env:"ADDR"`
+}
@@ -0,0 +1,29 @@
+package app
+
+type Server struct {
+	Addr string `env:"ADDR" default:":8080"`
+	TLS  TLS    `env:"TLS"`
+}
+
+type TLS struct {
+	Cert string `env:"CERT" file:"true"`
+}
+
+type Node struct {
+	Name string `env:"NAME"`
+	Next *Node  `env:"NEXT"`
+}
+
+type base struct {
+	Region string `env:"REGION"`
+}
+
+type Hidden struct {
+	base
+	*TLS
+	secret string
+}
+
+type Tagged struct {
+	secret string `env:"SECRET"`
+}
@@ -0,0 +1,132 @@
+// Package render writes the variable listings shared by config.Usage,
+// config.WriteDotEnvTemplate and the envdoc command.
+package render
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+const Redacted = "[REDACTED]"
+
+// Entry describes one variable. The default of a secret entry is redacted
+// in every format and left blank in .env templates.
+type Entry struct {
+	Name        string `json:"name"`
+	Field       string `json:"field"`
+	Type        string `json:"type"`
+	Default     string `json:"default,omitempty"`
+	Required    bool   `json:"required"`
+	Description string `json:"description,omitempty"`
+	Secret      bool   `json:"-"`
+}
+
+func (e Entry) redacted() string {
+	if e.Secret && e.Default != "" {
+		return Redacted
+	}
+
+	return e.Default
+}
+
+func Text(w io.Writer, entries []Entry) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tTYPE\tDEFAULT\tREQUIRED\tDESCRIPTION")
+	for _, e := range entries {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", e.Name, e.Type, e.redacted(), yesNo(e.Required), e.Description)
+	}
+
+	return tw.Flush()
+}
+
+func Markdown(w io.Writer, entries []Entry) error {
+	if _, err := fmt.Fprint(w, "| Name | Type | Default | Required | Description |\n|------|------|---------|----------|-------------|\n"); err != nil {
+		return err
+	}
+
+	for _, e := range entries {
+		if _, err := fmt.Fprintf(w, "| `%s` | `%s` | %s | %s | %s |\n", e.Name, e.Type, Code(e.redacted()), yesNo(e.Required), Escape(e.Description)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func JSON(w io.Writer, entries []Entry) error {
+	out := make([]Entry, 0, len(entries))
+	for _, e := range entries {
+		e.Default = e.redacted()
+		out = append(out, e)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
+
+// DotEnv writes a commented .env file pre-filled with the defaults.
+func DotEnv(w io.Writer, entries []Entry) error {
+	bw := bufio.NewWriter(w)
+	for n, e := range entries {
+		if n > 0 {
+			bw.WriteString("\n")
+		}
+
+		if e.Description != "" {
+			for _, line := range strings.Split(e.Description, "\n") {
+				bw.WriteString("# " + line + "\n")
+			}
+		}
+
+		bw.WriteString("# type: " + e.Type)
+		if e.Required {
+			bw.WriteString(" (required)")
+		}
+		bw.WriteString("\n")
+
+		val := e.Default
+		if e.Secret {
+			val = ""
+		}
+
+		bw.WriteString(e.Name + "=" + quote(val) + "\n")
+	}
+
+	return bw.Flush()
+}
+
+// Code formats s as inline markdown code, or as nothing if it is empty.
+func Code(s string) string {
+	if s == "" {
+		return ""
+	}
+
+	return "`" + Escape(s) + "`"
+}
+
+// Escape makes s safe inside a markdown table cell.
+func Escape(s string) string {
+	return strings.NewReplacer("|", `\|`, "\n", " ").Replace(s)
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+
+	return "no"
+}
+
+func quote(s string) string {
+	if strings.ContainsAny(s, " \t\r\n#'\"\\$") {
+		return strconv.Quote(s)
+	}
+
+	return s
+}
@@ -0,0 +1,57 @@
+package render
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+var entries = []Entry{
+	{Name: "HOST", Field: "Host", Type: "string", Default: "a|b", Description: "listen\nhost"},
+	{Name: "TOKEN", Field: "Token", Type: "string", Default: "hunter2", Required: true, Secret: true},
+}
+
+func TestRender(t *testing.T) {
+	for name, fn := range map[string]func(*bytes.Buffer, []Entry) error{
+		"text":     func(b *bytes.Buffer, e []Entry) error { return Text(b, e) },
+		"markdown": func(b *bytes.Buffer, e []Entry) error { return Markdown(b, e) },
+		"json":     func(b *bytes.Buffer, e []Entry) error { return JSON(b, e) },
+		"dotenv":   func(b *bytes.Buffer, e []Entry) error { return DotEnv(b, e) },
+	} {
+		var buf bytes.Buffer
+		if err := fn(&buf, entries); err != nil {
+			t.Error(err)
+		}
+
+		if s := buf.String(); strings.Contains(s, "hunter2") || !strings.Contains(s, "TOKEN") {
+			t.Error("render", name, s)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := Markdown(&buf, entries); err != nil {
+		t.Error(err)
+	}
+
+	if !strings.Contains(buf.String(), "| `HOST` | `string` | `a\\|b` | no | listen host |\n| `TOKEN` | `string` | `[REDACTED]` | yes |  |\n") {
+		t.Error("render markdown", buf.String())
+	}
+
+	buf.Reset()
+	if err := DotEnv(&buf, entries); err != nil {
+		t.Error(err)
+	}
+
+	if buf.String() != "# listen\n# host\n# type: string\nHOST=a|b\n\n# type: string (required)\nTOKEN=\n" {
+		t.Error("render dotenv", buf.String())
+	}
+
+	buf.Reset()
+	if err := JSON(&buf, nil); err != nil || buf.String() != "[]\n" {
+		t.Error("render json empty", buf.String(), err)
+	}
+
+	if quote("a b") != `"a b"` || quote("ab") != "ab" {
+		t.Error("quote")
+	}
+}
@@ -0,0 +1,152 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type Marshaler interface {
+	MarshalENV() ([]byte, error)
+}
+
+// Marshal renders i as the variables ReadENV reads it from. Nil pointers are
+// left out. Empty values read back as unset, so a field holding "" with a
+// non-empty default will come back as the default. Fields tagged
+// file:"true" are skipped: they hold a file's contents, while their
+// variable names the file.
+func Marshal(i interface{}) (map[string]string, error) {
+	v := reflect.ValueOf(i)
+	m := map[string]string{}
+	for _, f := range fields(v.Type()) {
+		if f.Tag.Get(filName) == "true" {
+			continue
+		}
+
+		fv, ok := fieldByIndex(v, f.Index)
+		if !ok {
+			continue
+		}
+
+		val, ok, err := encode(fv)
+		if err != nil {
+			return nil, fmt.Errorf("%s (%s): %w", f.Path, f.Name, err)
+		}
+
+		if ok {
+			m[f.Name] = val
+		}
+	}
+
+	return m, nil
+}
+
+// MarshalEnviron returns Marshal's result as sorted KEY=value pairs, ready
+// for exec.Cmd.Env. It returns nil if i cannot be marshaled.
+func MarshalEnviron(i interface{}) []string {
+	m, err := Marshal(i)
+	if err != nil {
+		return nil
+	}
+
+	env := make([]string, 0, len(m))
+	for k, v := range m {
+		env = append(env, k+"="+v)
+	}
+	sort.Strings(env)
+
+	return env
+}
+
+// encode formats a single value; ok is false for nil pointers.
+func encode(v reflect.Value) (string, bool, error) {
+	if m, ok := marshaler(v); ok {
+		data, err := m.MarshalENV()
+		return string(data), err == nil, err
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return "", false, nil
+		}
+
+		return encode(v.Elem())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), true, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10), true, nil
+	case reflect.Float32:
+		return strconv.FormatFloat(v.Float(), 'g', -1, 32), true, nil
+	case reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'g', -1, 64), true, nil
+	case reflect.String:
+		return v.String(), true, nil
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), true, nil
+	case reflect.Slice:
+		vals := make([]string, v.Len())
+		for i := range vals {
+			val, _, err := encode(v.Index(i))
+			if err != nil {
+				return "", false, err
+			}
+
+			if strings.Contains(val, delimiterVal) {
+				return "", false, fmt.Errorf("element %q contains %q", val, delimiterVal)
+			}
+			vals[i] = val
+		}
+
+		return strings.Join(vals, delimiterVal), true, nil
+	case reflect.Map:
+		keys := make([]string, 0, v.Len())
+		vals := map[string]string{}
+		for iter := v.MapRange(); iter.Next(); {
+			key := iter.Key().String()
+			if strings.Contains(key, delimiterVal) || strings.Contains(key, delimiterMap) {
+				return "", false, fmt.Errorf("key %q contains %q or %q", key, delimiterVal, delimiterMap)
+			}
+
+			val, _, err := encode(iter.Value())
+			if err != nil {
+				return "", false, err
+			}
+
+			if strings.Contains(val, delimiterVal) {
+				return "", false, fmt.Errorf("value %q contains %q", val, delimiterVal)
+			}
+
+			keys = append(keys, key)
+			vals[key] = val
+		}
+		sort.Strings(keys)
+
+		kvs := make([]string, len(keys))
+		for i, key := range keys {
+			kvs[i] = key + delimiterMap + vals[key]
+		}
+
+		return strings.Join(kvs, delimiterVal), true, nil
+	}
+
+	return "", false, errors.New("type error")
+}
+
+func marshaler(v reflect.Value) (Marshaler, bool) {
+	if v.Kind() != reflect.Ptr && v.CanAddr() {
+		if m, ok := v.Addr().Interface().(Marshaler); ok {
+			return m, true
+		}
+	}
+
+	if v.Kind() == reflect.Ptr && v.IsNil() || !v.CanInterface() {
+		return nil, false
+	}
+
+	m, ok := v.Interface().(Marshaler)
+	return m, ok
+}
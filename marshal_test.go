@@ -0,0 +1,126 @@
+package config
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type Level int
+
+func (l *Level) UnmarshalENV(data []byte) error {
+	switch bytes.NewBuffer(data).String() {
+	case "debug":
+		*l = 1
+	case "info", "":
+		*l = 2
+	}
+	return nil
+}
+
+func (l Level) MarshalENV() ([]byte, error) {
+	if l == 1 {
+		return []byte("debug"), nil
+	}
+	return []byte("info"), nil
+}
+
+type ConfigMarshal struct {
+	ConfigENV
+	Level    Level             `env:"LEVEL"`
+	Timeout  *int              `env:"TIMEOUT"`
+	Empty    []int             `env:"EMPTY"`
+	Password SecretOf[string]  `env:"PASSWORD"`
+	Labels   map[string]string `env:"LABELS"`
+}
+
+func TestMarshal(t *testing.T) {
+	src := MapSource{"LEVEL": "debug", "PASSWORD": "hunter2", "LABELS": "b:2,a:1"}
+	for k, v := range envs {
+		src[k] = v
+	}
+
+	c := &ConfigMarshal{}
+
+	if err := ReadENV(c, WithSources(src)); err != nil {
+		t.Error(err)
+	}
+
+	m, err := Marshal(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if m["LEVEL"] != "debug" || m["PASSWORD"] != "hunter2" || m["LABELS"] != "a:1,b:2" || m["STRUCT_STRING"] != "test_string_2" {
+		t.Error("marshal values", m["LEVEL"], m["PASSWORD"], m["LABELS"], m["STRUCT_STRING"])
+	}
+
+	if _, ok := m["STRUCT_PAS_STRING"]; ok {
+		t.Error("marshal STRUCT_PAS_STRING")
+	}
+
+	if m["TIMEOUT"] != "0" {
+		t.Error("marshal TIMEOUT")
+	}
+
+	if n, err := Marshal(&ConfigMarshal{}); err != nil {
+		t.Error(err)
+	} else if _, ok := n["TIMEOUT"]; ok {
+		t.Error("marshal nil TIMEOUT")
+	}
+
+	r := &ConfigMarshal{}
+
+	if err := ReadENV(r, WithSources(MapSource(m))); err != nil {
+		t.Error(err)
+	}
+
+	if !reflect.DeepEqual(c, r) {
+		t.Error("marshal round trip")
+	}
+
+	env := MarshalEnviron(c)
+	if len(env) != len(m) || !strings.HasPrefix(env[0], "ARRAY_BOOL=") {
+		t.Error("marshal environ", env[0])
+	}
+
+	c.ArrayString = []string{"a,b"}
+
+	if _, err := Marshal(c); err == nil || !strings.Contains(err.Error(), "ArrayString (ARRAY_STRING)") {
+		t.Error("marshal delimiter", err)
+	}
+
+	if MarshalEnviron(c) != nil {
+		t.Error("marshal environ error")
+	}
+}
+
+func TestMarshalFileField(t *testing.T) {
+	type config struct {
+		Host string `env:"HOST"`
+		CA   string `env:"CA" file:"true"`
+	}
+
+	ca := writeFile(t, "ca.pem", "certificate")
+
+	c := &config{}
+	if err := ReadENV(c, WithSources(MapSource{"HOST": "h", "CA": ca})); err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := Marshal(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := m["CA"]; ok || m["HOST"] != "h" {
+		t.Error("marshal file field", m)
+	}
+
+	m["CA"] = ca
+	r := &config{}
+	if err := ReadENV(r, WithSources(MapSource(m))); err != nil || *r != *c {
+		t.Error("marshal file field round trip", r, err)
+	}
+}
@@ -0,0 +1,15 @@
+package config
+
+// Merge decodes into i like ReadENV but only touches fields whose variable
+// or default tag is set, leaving every other value in i as it is. Slices and
+// maps that are set are replaced unless AppendSlices or MergeMaps is given.
+// SetDefaults is not called, as it would overwrite the values already in i.
+func Merge(i interface{}, opts ...Option) error {
+	return ReadENV(i, append([]Option{KeepPresets(), merging()}, opts...)...)
+}
+
+func merging() Option {
+	return func(o *options) {
+		o.merge = true
+	}
+}
@@ -0,0 +1,136 @@
+package config
+
+import "testing"
+
+type ConfigMerge struct {
+	Host    string            `env:"HOST"`
+	Port    int               `env:"PORT"`
+	Level   string            `env:"LEVEL" default:"info"`
+	Hosts   []string          `env:"HOSTS"`
+	Labels  map[string]string `env:"LABELS"`
+	Timeout *int              `env:"TIMEOUT"`
+	Retries *int              `env:"RETRIES" default:"3"`
+	TLS     *struct {
+		Cert string `env:"CERT"`
+	} `env:"TLS"`
+}
+
+func TestMerge(t *testing.T) {
+	base := func() *ConfigMerge {
+		return &ConfigMerge{
+			Host:   "file.local",
+			Port:   8080,
+			Level:  "debug",
+			Hosts:  []string{"a"},
+			Labels: map[string]string{"team": "core"},
+		}
+	}
+
+	src := MapSource{"PORT": "9090", "HOSTS": "b", "LABELS": "tier:1"}
+
+	c := base()
+
+	if err := Merge(c, WithSources(src)); err != nil {
+		t.Error(err)
+	}
+
+	if c.Host != "file.local" || c.Port != 9090 || c.Level != "info" {
+		t.Error("merge scalars", c)
+	}
+
+	if len(c.Hosts) != 1 || c.Hosts[0] != "b" || len(c.Labels) != 1 || c.Labels["tier"] != "1" {
+		t.Error("merge replace", c.Hosts, c.Labels)
+	}
+
+	if c.Timeout != nil || c.TLS != nil {
+		t.Error("merge nil pointers")
+	}
+
+	if c.Retries == nil || *c.Retries != 3 {
+		t.Error("merge default pointer RETRIES")
+	}
+
+	c = base()
+
+	if err := Merge(c, WithSources(src), AppendSlices(), MergeMaps()); err != nil {
+		t.Error(err)
+	}
+
+	if len(c.Hosts) != 2 || c.Hosts[0] != "a" || c.Hosts[1] != "b" {
+		t.Error("merge append HOSTS", c.Hosts)
+	}
+
+	if len(c.Labels) != 2 || c.Labels["team"] != "core" || c.Labels["tier"] != "1" {
+		t.Error("merge keys LABELS", c.Labels)
+	}
+
+	c = base()
+
+	if err := Merge(c, WithSources(MapSource{})); err != nil {
+		t.Error(err)
+	}
+
+	if c.Host != "file.local" || c.Port != 8080 || len(c.Hosts) != 1 || len(c.Labels) != 1 {
+		t.Error("merge untouched", c)
+	}
+}
+
+func TestMergeDefaulter(t *testing.T) {
+	c := &ConfigDefaults{Workers: 2, Level: "error", Hosts: []string{"h"}}
+
+	if err := Merge(c, WithSources(MapSource{})); err != nil {
+		t.Error(err)
+	}
+
+	if c.Workers != 2 || len(c.Hosts) != 1 || c.Hosts[0] != "h" || len(c.Upstreams) != 0 {
+		t.Error("merge skips SetDefaults", c)
+	}
+}
+
+func TestMergeRequired(t *testing.T) {
+	type config struct {
+		Token string `env:"TOKEN" required:"true"`
+	}
+
+	if err := Merge(&config{Token: "t0ken"}, WithSources(MapSource{})); err != nil {
+		t.Error("merge required preset", err)
+	}
+
+	if err := ReadENV(&config{Token: "t0ken"}, WithSources(MapSource{}), KeepPresets()); err != nil {
+		t.Error("keep presets required preset", err)
+	}
+
+	if err := Merge(&config{}, WithSources(MapSource{})); err == nil {
+		t.Error("merge required")
+	}
+
+	if err := ReadENV(&config{Token: "t0ken"}, WithSources(MapSource{})); err == nil {
+		t.Error("required without presets")
+	}
+}
+
+func TestMergeAppendDefault(t *testing.T) {
+	type config struct {
+		Hosts []string `env:"HOSTS" default:"d"`
+	}
+
+	c := &config{Hosts: []string{"a"}}
+
+	if err := Merge(c, WithSources(MapSource{}), AppendSlices()); err != nil {
+		t.Error(err)
+	}
+
+	if len(c.Hosts) != 1 || c.Hosts[0] != "d" {
+		t.Error("merge append default HOSTS", c.Hosts)
+	}
+
+	c = &config{Hosts: []string{"a"}}
+
+	if err := Merge(c, WithSources(MapSource{"HOSTS": "b"}), AppendSlices()); err != nil {
+		t.Error(err)
+	}
+
+	if len(c.Hosts) != 2 || c.Hosts[0] != "a" || c.Hosts[1] != "b" {
+		t.Error("merge append source HOSTS", c.Hosts)
+	}
+}
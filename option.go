@@ -0,0 +1,171 @@
+package config
+
+import (
+	"os"
+	"time"
+)
+
+type Option func(*options)
+
+type options struct {
+	sources      []Source
+	flags        *FlagSource
+	trimNewline  bool
+	expand       bool
+	presets      bool
+	presetsFirst bool
+	optionalPtrs bool
+	merge        bool
+	appendSlices bool
+	mergeMaps    bool
+	strict       bool
+	maxDepth     int
+	unknown      *string
+	warn         func(error)
+
+	watchFiles   []string
+	pollInterval time.Duration
+	signals      []os.Signal
+	static       *StaticPolicy
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{sources: []Source{EnvSource{}}, pollInterval: time.Second}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	if o.flags != nil {
+		o.sources = append([]Source{o.flags}, o.sources...)
+	}
+
+	return o
+}
+
+// KeepPresets treats values already in the struct as defaults: a field whose
+// variable and default tag are both unset keeps its value instead of being
+// cleared. Structs implementing Defaulter get this automatically.
+func KeepPresets() Option {
+	return func(o *options) {
+		o.presets = true
+	}
+}
+
+// PresetsOverTags makes non-zero preset values take priority over default
+// tags, giving env > preset > tag. It implies KeepPresets.
+func PresetsOverTags() Option {
+	return func(o *options) {
+		o.presets = true
+		o.presetsFirst = true
+	}
+}
+
+// OptionalPointers treats every nil pointer field as if it were tagged
+// optional:"true".
+func OptionalPointers() Option {
+	return func(o *options) {
+		o.optionalPtrs = true
+	}
+}
+
+// AppendSlices appends elements read from a source to a slice already in the
+// struct instead of replacing it. Default tags still replace the slice.
+func AppendSlices() Option {
+	return func(o *options) {
+		o.appendSlices = true
+	}
+}
+
+// MergeMaps adds decoded keys to a map already in the struct instead of
+// replacing it.
+func MergeMaps() Option {
+	return func(o *options) {
+		o.mergeMaps = true
+	}
+}
+
+// TrimNewline strips a single trailing newline from values read from files,
+// as written by most secret stores.
+func TrimNewline() Option {
+	return func(o *options) {
+		o.trimNewline = true
+	}
+}
+
+// MaxDepth lets recursive config types be decoded n levels deep, leaving
+// deeper pointers nil. Without it a recursive type is an error.
+func MaxDepth(n int) Option {
+	return func(o *options) {
+		o.maxDepth = n
+	}
+}
+
+// WithSources replaces the environment with the given sources. Earlier
+// sources take priority over later ones.
+func WithSources(sources ...Source) Option {
+	return func(o *options) {
+		o.sources = sources
+	}
+}
+
+// Expand enables ${VAR}, ${VAR:-fallback} and ${VAR:?error} references in
+// values and defaults. Use $$ for a literal $.
+func Expand() Option {
+	return func(o *options) {
+		o.expand = true
+	}
+}
+
+// CheckUnknown reports variables starting with prefix that no field reads.
+// They are passed to the OnWarning handler, or returned as errors in Strict
+// mode.
+func CheckUnknown(prefix string) Option {
+	return func(o *options) {
+		o.unknown = &prefix
+	}
+}
+
+// Strict turns warnings about the sources into errors.
+func Strict() Option {
+	return func(o *options) {
+		o.strict = true
+	}
+}
+
+func OnWarning(fn func(error)) Option {
+	return func(o *options) {
+		o.warn = fn
+	}
+}
+
+// WatchFiles makes a Watcher reload whenever one of the files changes.
+func WatchFiles(paths ...string) Option {
+	return func(o *options) {
+		o.watchFiles = append(o.watchFiles, paths...)
+	}
+}
+
+// PollInterval sets how often a Watcher checks its files. The default is one
+// second.
+func PollInterval(d time.Duration) Option {
+	return func(o *options) {
+		o.pollInterval = d
+	}
+}
+
+// ReloadOn makes a Watcher reload when the process receives one of the
+// signals, typically syscall.SIGHUP.
+func ReloadOn(signals ...os.Signal) Option {
+	return func(o *options) {
+		o.signals = append(o.signals, signals...)
+	}
+}
+
+// StaticChanges sets how a Watcher treats reloads that change fields not
+// tagged dynamic:"true". The default is FlagStatic if the config declares
+// any dynamic field and ApplyStatic otherwise.
+func StaticChanges(p StaticPolicy) Option {
+	return func(o *options) {
+		o.static = &p
+	}
+}
@@ -0,0 +1,51 @@
+package config
+
+import "reflect"
+
+const optName = "optional"
+
+// optional reports whether a nil pointer field should be left nil because
+// none of the variables below it is set in the sources. When merging,
+// default tags count as set and every pointer is optional.
+func (d *decoder) optional(result reflect.Value, f *fieldPlan) bool {
+	if result.Kind() != reflect.Ptr || !result.IsNil() {
+		return false
+	}
+
+	if !d.optionalPtrs && !d.merge && !f.optional {
+		return false
+	}
+
+	if f.leaf {
+		for _, a := range f.aliases {
+			if d.present(a) {
+				return false
+			}
+		}
+
+		return !d.present(f.tag) && !(d.merge && f.def != "")
+	}
+
+	elemType := f.field.Type
+	for elemType.Kind() == reflect.Ptr {
+		elemType = elemType.Elem()
+	}
+
+	for _, v := range appendFields(nil, elemType, f.tag, "", nil, false, nil) {
+		if d.present(v.Name) || d.merge && v.Default != "" {
+			return false
+		}
+
+		for _, a := range v.Aliases {
+			if d.present(a) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+func (d *decoder) present(tag string) bool {
+	return tag != "" && (d.lookup(tag) != "" || d.lookup(tag+fileSuffix) != "")
+}
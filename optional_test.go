@@ -0,0 +1,56 @@
+package config
+
+import "testing"
+
+type TLSConfig struct {
+	Cert string `env:"CERT" default:"cert.pem"`
+	Key  string `env:"KEY" default:"key.pem"`
+}
+
+type ConfigOptional struct {
+	TLS     *TLSConfig `env:"TLS" optional:"true"`
+	Timeout *int       `env:"TIMEOUT" default:"30" optional:"true"`
+	Proxy   *struct {
+		URL string `env:"URL" default:"http://proxy"`
+	} `env:"PROXY"`
+}
+
+func TestOptional(t *testing.T) {
+	c := &ConfigOptional{}
+
+	if err := ReadENV(c, WithSources(MapSource{})); err != nil {
+		t.Error(err)
+	}
+
+	if c.TLS != nil || c.Timeout != nil {
+		t.Error("optional unset")
+	}
+
+	if c.Proxy == nil || c.Proxy.URL != "http://proxy" {
+		t.Error("optional untagged PROXY")
+	}
+
+	c = &ConfigOptional{}
+
+	if err := ReadENV(c, WithSources(MapSource{"TLS_CERT": "tls.pem", "TIMEOUT": "5"})); err != nil {
+		t.Error(err)
+	}
+
+	if c.TLS == nil || c.TLS.Cert != "tls.pem" || c.TLS.Key != "key.pem" {
+		t.Error("optional TLS activated", c.TLS)
+	}
+
+	if c.Timeout == nil || *c.Timeout != 5 {
+		t.Error("optional TIMEOUT activated")
+	}
+
+	c = &ConfigOptional{}
+
+	if err := ReadENV(c, WithSources(MapSource{}), OptionalPointers()); err != nil {
+		t.Error(err)
+	}
+
+	if c.Proxy != nil {
+		t.Error("optional pointers PROXY")
+	}
+}
@@ -0,0 +1,242 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sync"
+
+	"github.com/GMTror/config/internal/envtag"
+)
+
+type decodeFunc func(d *decoder, result reflect.Value, tag, defaultVal string) error
+
+// structPlan is the compiled form of a struct type under a given variable
+// prefix: tags are parsed, names resolved and decoders picked once.
+type structPlan struct {
+	fields    []fieldPlan
+	defaulter bool
+	validator bool
+
+	once     sync.Once
+	conflict error
+}
+
+type fieldPlan struct {
+	field    reflect.StructField
+	index    int
+	tag      string
+	aliases  []string
+	def      string
+	leaf     bool
+	file     bool
+	required bool
+	optional bool
+	secret   bool
+	dynamic  bool
+	checks   []constraint
+	decode   decodeFunc
+	err      error
+}
+
+type constraint struct {
+	name string
+	arg  string
+	re   *regexp.Regexp
+	err  error
+}
+
+type planKey struct {
+	t      reflect.Type
+	prefix string
+}
+
+var (
+	plans    sync.Map // map[planKey]*structPlan
+	decoders sync.Map // map[reflect.Type]decodeFunc
+)
+
+var (
+	defaulterType = reflect.TypeOf((*Defaulter)(nil)).Elem()
+	validatorType = reflect.TypeOf((*Validator)(nil)).Elem()
+)
+
+func planFor(t reflect.Type, prefix string) *structPlan {
+	key := planKey{t: t, prefix: prefix}
+	if p, ok := plans.Load(key); ok {
+		return p.(*structPlan)
+	}
+
+	p, _ := plans.LoadOrStore(key, buildPlan(t, prefix))
+	return p.(*structPlan)
+}
+
+func buildPlan(t reflect.Type, prefix string) *structPlan {
+	p := &structPlan{
+		defaulter: reflect.PtrTo(t).Implements(defaulterType),
+		validator: reflect.PtrTo(t).Implements(validatorType),
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		fieldType := t.Field(i)
+		rawTag, tagged := fieldType.Tag.Lookup(tagName)
+		bTag, err := envtag.Parse(rawTag)
+		if bTag.Name == pasName {
+			continue
+		}
+
+		if !settable(fieldType) {
+			if tagged {
+				p.fields = append(p.fields, fieldPlan{
+					field: fieldType,
+					index: i,
+					tag:   getTag(prefix, bTag.Name),
+					err:   fmt.Errorf("unexported field %s cannot be set; export it or remove its env tag", fieldType.Name),
+				})
+			}
+			continue
+		}
+
+		f := fieldPlan{
+			field:    fieldType,
+			index:    i,
+			tag:      getTag(prefix, bTag.Name),
+			def:      fieldType.Tag.Get(valName),
+			leaf:     isLeaf(fieldType),
+			file:     fieldType.Tag.Get(filName) == "true",
+			required: fieldType.Tag.Get(reqName) == "true",
+			optional: fieldType.Tag.Get(optName) == "true",
+			secret:   isSecret(fieldType),
+			dynamic:  fieldType.Tag.Get(dynName) == "true",
+			decode:   decoderFor(fieldType.Type),
+		}
+
+		switch {
+		case err != nil:
+			f.err = err
+		case f.leaf && bTag.Options():
+			f.err = fmt.Errorf("env tag options squash and inline apply to struct fields only")
+		case bTag.Squash:
+			f.tag = prefix
+		}
+
+		if f.leaf {
+			f.aliases = aliases(prefix, fieldType.Tag.Get(aliName))
+		}
+
+		for _, name := range []string{nzrName, minName, maxName, lenName, rexName, fmtName} {
+			arg, ok := fieldType.Tag.Lookup(name)
+			if !ok {
+				continue
+			}
+
+			c := constraint{name: name, arg: arg}
+			if name == rexName {
+				if c.re, c.err = regexp.Compile(arg); c.err != nil {
+					c.err = fmt.Errorf("invalid regexp tag: %v", c.err)
+				}
+			}
+			f.checks = append(f.checks, c)
+		}
+
+		p.fields = append(p.fields, f)
+	}
+
+	return p
+}
+
+// conflicts is computed on first use rather than in buildPlan, which would
+// otherwise recurse through plans of embedded recursive types.
+func (p *structPlan) conflicts(t reflect.Type) error {
+	p.once.Do(func() {
+		p.conflict = conflicts(p, t)
+	})
+
+	return p.conflict
+}
+
+// conflicts reports a variable read both by a field of an embedded or
+// squashed struct and by another field of the struct around it.
+func conflicts(p *structPlan, t reflect.Type) error {
+	type reader struct {
+		path     string
+		embedded bool
+	}
+
+	seen := map[string]reader{}
+	add := func(name, path string, embedded bool) error {
+		if name == "" {
+			return nil
+		}
+
+		if r, ok := seen[name]; ok && (r.embedded || embedded) {
+			return fmt.Errorf("fields %s and %s both read %s", r.path, path, name)
+		}
+		seen[name] = reader{path: path, embedded: embedded}
+
+		return nil
+	}
+
+	for i := range p.fields {
+		f := &p.fields[i]
+		if f.err != nil {
+			continue
+		}
+
+		if f.leaf {
+			if err := add(f.tag, f.field.Name, false); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if tag, _ := envtag.Parse(f.field.Tag.Get(tagName)); !f.field.Anonymous && tag.Name != "" {
+			continue
+		}
+
+		elemType := f.field.Type
+		for elemType.Kind() == reflect.Ptr {
+			elemType = elemType.Elem()
+		}
+
+		for _, v := range appendFields(nil, elemType, f.tag, f.field.Name, nil, false, []reflect.Type{t}) {
+			if err := add(v.Name, v.Path, true); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// settable reports whether the decoder can set a field: it is exported, or
+// an embedded struct whose exported fields are promoted. Embedded pointers
+// to unexported types cannot be allocated.
+func settable(field reflect.StructField) bool {
+	if field.IsExported() {
+		return true
+	}
+
+	return field.Anonymous && field.Type.Kind() == reflect.Struct
+}
+
+func decoderFor(t reflect.Type) decodeFunc {
+	if fn, ok := decoders.Load(t); ok {
+		return fn.(decodeFunc)
+	}
+
+	fn := kindDecoder(t)
+	if t.Kind() != reflect.Ptr && reflect.PtrTo(t).Implements(unmarshalerType) {
+		kindFn := fn
+		fn = func(d *decoder, result reflect.Value, tag, defaultVal string) error {
+			if result.CanAddr() && result.CanInterface() {
+				return d.unmarshal(result.Addr().Interface().(Unmarshaler), tag, defaultVal)
+			}
+
+			return kindFn(d, result, tag, defaultVal)
+		}
+	}
+
+	decoders.Store(t, fn)
+	return fn
+}
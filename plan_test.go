@@ -0,0 +1,67 @@
+package config
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestPlanConcurrent(t *testing.T) {
+	plans.Clear()
+	decoders.Clear()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			c := &ConfigENV{}
+			if err := ReadENV(c, WithSources(MapSource(envs))); err != nil {
+				t.Error(err)
+			}
+
+			if c.Struct.Struct.String != "test_string_2" || len(c.ArrayBool) != 12 {
+				t.Error("plan concurrent decode")
+			}
+		}()
+	}
+	wg.Wait()
+
+	if p := planFor(reflect.TypeOf(ConfigENV{}), ""); len(p.fields) != 46 || p.fields[45].tag != "STRUCT" {
+		t.Error("plan fields")
+	}
+
+	if p := planFor(reflect.TypeOf(ConfigENV{}).Field(45).Type, "STRUCT"); len(p.fields) != 1 || p.fields[0].tag != "STRUCT" || p.fields[0].leaf {
+		t.Error("plan nested fields")
+	}
+}
+
+// BenchmarkReadENV decodes with warm plans, the steady state for repeated
+// loads of the same type.
+func BenchmarkReadENV(b *testing.B) {
+	src := WithSources(MapSource(envs))
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		if err := ReadENV(&ConfigENV{}, src); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkReadENVCold rebuilds every plan on each load, which is what
+// every ReadENV call did before plans were cached.
+func BenchmarkReadENVCold(b *testing.B) {
+	src := WithSources(MapSource(envs))
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		plans.Clear()
+		decoders.Clear()
+
+		if err := ReadENV(&ConfigENV{}, src); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// depth returns how many times t is already being decoded.
+func (d *decoder) depth(t reflect.Type) int {
+	n := 0
+	for _, s := range d.types {
+		if s == t {
+			n++
+		}
+	}
+
+	return n
+}
+
+// tooDeep reports whether a pointer to t should be left alone because t is
+// recursive and MaxDepth is reached.
+func (d *decoder) tooDeep(t reflect.Type) bool {
+	return d.maxDepth > 0 && t.Kind() == reflect.Struct && d.depth(t) >= d.maxDepth
+}
+
+// recursive returns an error naming the cycle when t is already being
+// decoded and no MaxDepth allows it.
+func (d *decoder) recursive(t reflect.Type) error {
+	n := d.depth(t)
+	if n == 0 || d.maxDepth > 0 && n < d.maxDepth {
+		return nil
+	}
+
+	var names []string
+	for i := len(d.types) - 1; i >= 0; i-- {
+		names = append([]string{d.types[i].String()}, names...)
+		if d.types[i] == t {
+			break
+		}
+	}
+
+	return fmt.Errorf("recursive type %s -> %s", strings.Join(names, " -> "), t)
+}
@@ -0,0 +1,83 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+type Node struct {
+	Name string `env:"NAME"`
+	Next *Node  `env:"NEXT"`
+}
+
+type Tree struct {
+	Value string `env:"VALUE"`
+	Left  *Tree  `env:"LEFT"`
+	Right *Branch
+}
+
+type Branch struct {
+	Tree *Tree `env:"RIGHT"`
+}
+
+func TestRecursiveType(t *testing.T) {
+	err := ReadENV(&Node{}, WithSources(MapSource{"NAME": "a"}))
+
+	var de *DecodeError
+	if !errors.As(err, &de) || de.Field != "Next" || de.Err.Error() != "recursive type config.Node -> config.Node" {
+		t.Error("recursive type", err)
+	}
+
+	err = ReadENV(&Tree{}, WithSources(MapSource{}))
+	if err == nil || !strings.HasSuffix(err.Error(), "recursive type config.Tree -> config.Tree") {
+		t.Error("recursive type Left", err)
+	}
+
+	n := &Node{}
+	n.Next = n
+	if err := ReadENV(n, WithSources(MapSource{}), KeepPresets()); err == nil {
+		t.Error("cyclic presets")
+	}
+}
+
+func TestMaxDepth(t *testing.T) {
+	n := &Node{}
+	if err := ReadENV(n, WithSources(MapSource{"NAME": "a", "NEXT_NAME": "b", "NEXT_NEXT_NAME": "c", "NEXT_NEXT_NEXT_NAME": "d"}), MaxDepth(3)); err != nil {
+		t.Error(err)
+	}
+
+	if n.Name != "a" || n.Next == nil || n.Next.Name != "b" || n.Next.Next == nil || n.Next.Next.Name != "c" || n.Next.Next.Next != nil {
+		t.Error("max depth", n)
+	}
+
+	tr := &Tree{}
+	if err := ReadENV(tr, WithSources(MapSource{"LEFT_VALUE": "l", "RIGHT_VALUE": "r"}), MaxDepth(2)); err != nil {
+		t.Error(err)
+	}
+
+	if tr.Left.Value != "l" || tr.Right.Tree.Value != "r" || tr.Left.Left != nil || tr.Right.Tree.Right.Tree != nil {
+		t.Error("max depth tree", tr)
+	}
+
+	n = &Node{}
+	if err := ReadENV(n, WithSources(MapSource{"NAME": "a"}), MaxDepth(5), OptionalPointers()); err != nil {
+		t.Error(err)
+	}
+
+	if n.Next != nil {
+		t.Error("max depth optional", n.Next)
+	}
+}
+
+func TestRecursiveUsage(t *testing.T) {
+	var b bytes.Buffer
+	if err := Usage(&Tree{}, &b, FormatText); err != nil {
+		t.Error(err)
+	}
+
+	if !strings.Contains(b.String(), "VALUE") || strings.Contains(b.String(), "LEFT_VALUE") || strings.Count(b.String(), "\n") != 2 {
+		t.Error("recursive usage", b.String())
+	}
+}
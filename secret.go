@@ -0,0 +1,138 @@
+package config
+
+import (
+	"fmt"
+	"log/slog"
+	"reflect"
+	"strconv"
+
+	"github.com/GMTror/config/internal/render"
+)
+
+const (
+	secName  = "secret"
+	redacted = render.Redacted
+)
+
+// Secret is a string that never prints its value. Use Reveal to read it.
+type Secret string
+
+func (s Secret) Reveal() string {
+	return string(s)
+}
+
+func (s Secret) String() string {
+	return redacted
+}
+
+func (s Secret) GoString() string {
+	return strconv.Quote(redacted)
+}
+
+func (s Secret) Format(f fmt.State, verb rune) {
+	formatRedacted(f, verb)
+}
+
+func (s Secret) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.Quote(redacted)), nil
+}
+
+func (s Secret) LogValue() slog.Value {
+	return slog.StringValue(redacted)
+}
+
+func (s Secret) secret() {}
+
+// SecretOf is a Secret holding a value of any decodable type.
+type SecretOf[T any] struct {
+	value T
+}
+
+func (s SecretOf[T]) Reveal() T {
+	return s.value
+}
+
+func (s SecretOf[T]) String() string {
+	return redacted
+}
+
+func (s SecretOf[T]) GoString() string {
+	return strconv.Quote(redacted)
+}
+
+func (s SecretOf[T]) Format(f fmt.State, verb rune) {
+	formatRedacted(f, verb)
+}
+
+func (s SecretOf[T]) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.Quote(redacted)), nil
+}
+
+func (s SecretOf[T]) LogValue() slog.Value {
+	return slog.StringValue(redacted)
+}
+
+func (s *SecretOf[T]) UnmarshalENV(data []byte) error {
+	d := newDecoder(nil)
+	return d.decode(reflect.ValueOf(&s.value).Elem(), "", string(data))
+}
+
+func (s SecretOf[T]) MarshalENV() ([]byte, error) {
+	val, _, err := encode(reflect.ValueOf(s.value))
+	return []byte(val), err
+}
+
+func (s SecretOf[T]) secret() {}
+
+type secretValue interface {
+	secret()
+}
+
+var secretType = reflect.TypeOf((*secretValue)(nil)).Elem()
+
+func formatRedacted(f fmt.State, verb rune) {
+	if verb == 'q' || verb == 'v' && f.Flag('#') {
+		fmt.Fprint(f, strconv.Quote(redacted))
+		return
+	}
+
+	fmt.Fprint(f, redacted)
+}
+
+// isSecret reports whether the field is tagged secret:"true" or holds a
+// Secret type.
+func isSecret(field reflect.StructField) bool {
+	if field.Tag.Get(secName) == "true" {
+		return true
+	}
+
+	t := field.Type
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	return t.Implements(secretType)
+}
+
+// redactError hides the message of any error from a secret field, since
+// parse errors commonly quote the offending value. The cause is kept for
+// errors.Is and errors.As.
+func redactError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return &redactedError{err: err}
+}
+
+type redactedError struct {
+	err error
+}
+
+func (e *redactedError) Error() string {
+	return "invalid value " + redacted
+}
+
+func (e *redactedError) Unwrap() error {
+	return e.err
+}
@@ -0,0 +1,99 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+type ConfigSecret struct {
+	Password Secret              `env:"SECRET_PASSWORD" default:"hunter2"`
+	Pin      SecretOf[int]       `env:"SECRET_PIN" default:"1234"`
+	Hosts    *SecretOf[[]string] `env:"SECRET_HOSTS" default:"a,b"`
+	Token    string              `env:"SECRET_TOKEN" secret:"true"`
+	Port     int                 `env:"SECRET_PORT" secret:"true"`
+}
+
+func TestSecret(t *testing.T) {
+	c := &ConfigSecret{}
+
+	if err := ReadENV(c, WithSources(MapSource{"SECRET_TOKEN": "token"})); err != nil {
+		t.Error(err)
+	}
+
+	if c.Password.Reveal() != "hunter2" {
+		t.Error("reveal SECRET_PASSWORD")
+	}
+
+	if c.Pin.Reveal() != 1234 {
+		t.Error("reveal SECRET_PIN")
+	}
+
+	if hosts := c.Hosts.Reveal(); len(hosts) != 2 || hosts[0] != "a" || hosts[1] != "b" {
+		t.Error("reveal SECRET_HOSTS")
+	}
+
+	for _, format := range []string{"%v", "%+v", "%#v", "%s", "%q", "%d", "%x"} {
+		if s := fmt.Sprintf(format, c); strings.Contains(s, "hunter2") || strings.Contains(s, "1234") {
+			t.Error("format", format, s)
+		}
+	}
+
+	if s := c.Password.String() + c.Password.GoString() + c.Pin.String() + c.Pin.GoString(); strings.Contains(s, "hunter2") || strings.Contains(s, "1234") {
+		t.Error("stringer", s)
+	}
+
+	data, err := json.Marshal(c)
+	if err != nil {
+		t.Error(err)
+	}
+
+	if s := string(data); strings.Contains(s, "hunter2") || strings.Contains(s, "1234") || !strings.Contains(s, `"Password":"[REDACTED]"`) {
+		t.Error("json", s)
+	}
+
+	var buf bytes.Buffer
+	slog.New(slog.NewTextHandler(&buf, nil)).Info("config", "password", c.Password, "pin", c.Pin)
+	if s := buf.String(); strings.Contains(s, "hunter2") || strings.Contains(s, "1234") {
+		t.Error("slog", s)
+	}
+
+	err = ReadENV(&ConfigSecret{}, WithSources(MapSource{"SECRET_PORT": "p4ssw0rd"}))
+	if err == nil || strings.Contains(err.Error(), "p4ssw0rd") || !strings.Contains(err.Error(), redacted) {
+		t.Error("redact error SECRET_PORT", err)
+	}
+
+	err = ReadENV(&ConfigSecret{}, WithSources(MapSource{"SECRET_PIN": "p4ssw0rd"}))
+	if err == nil || strings.Contains(err.Error(), "p4ssw0rd") {
+		t.Error("redact error SECRET_PIN", err)
+	}
+}
+
+type SecretDuration time.Duration
+
+func (s *SecretDuration) UnmarshalENV(data []byte) error {
+	d, err := time.ParseDuration(string(data))
+	*s = SecretDuration(d)
+	return err
+}
+
+func TestSecretUnmarshalerError(t *testing.T) {
+	type config struct {
+		T *SecretDuration `env:"T" secret:"true"`
+	}
+
+	err := ReadENV(&config{}, WithSources(MapSource{"T": "hunter2"}))
+	if err == nil || strings.Contains(err.Error(), "hunter2") || err.Error() != "T (T): invalid value [REDACTED]" {
+		t.Error("redact unmarshaler error", err)
+	}
+
+	var de *DecodeError
+	if !errors.As(err, &de) || errors.Unwrap(de.Err) == nil || !strings.Contains(errors.Unwrap(de.Err).Error(), "hunter2") {
+		t.Error("redact keeps cause", err)
+	}
+}
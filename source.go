@@ -0,0 +1,82 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+type Source interface {
+	Lookup(key string) (string, bool)
+}
+
+// Lister is implemented by sources that can enumerate their keys.
+type Lister interface {
+	Keys() []string
+}
+
+type EnvSource struct{}
+
+func (EnvSource) Lookup(key string) (string, bool) {
+	return os.LookupEnv(key)
+}
+
+func (EnvSource) Keys() []string {
+	var keys []string
+	for _, kv := range os.Environ() {
+		if i := strings.Index(kv, "="); i > 0 {
+			keys = append(keys, kv[:i])
+		}
+	}
+
+	return keys
+}
+
+func (EnvSource) String() string {
+	return "env"
+}
+
+type MapSource map[string]string
+
+func (s MapSource) Lookup(key string) (string, bool) {
+	val, ok := s[key]
+	return val, ok
+}
+
+func (s MapSource) Keys() []string {
+	keys := make([]string, 0, len(s))
+	for k := range s {
+		keys = append(keys, k)
+	}
+
+	return keys
+}
+
+func (s MapSource) String() string {
+	return "map"
+}
+
+// lookup returns the first non-empty value of key in the active sources.
+func (d *decoder) lookup(key string) string {
+	val, _ := d.source(key)
+	return val
+}
+
+// source is like lookup but also returns the source the value came from.
+func (d *decoder) source(key string) (string, Source) {
+	for _, s := range d.sources {
+		if val, ok := s.Lookup(key); ok && val != "" {
+			return val, s
+		}
+	}
+
+	return "", nil
+}
+
+func sourceName(s Source) string {
+	if n, ok := s.(fmt.Stringer); ok {
+		return n.String()
+	}
+
+	return fmt.Sprintf("%T", s)
+}
@@ -0,0 +1,19 @@
+package config
+
+import "testing"
+
+func TestSources(t *testing.T) {
+	c := &ConfigExpand{}
+
+	if err := ReadENV(c, WithSources(MapSource{"LITERAL": ""}, MapSource{"LITERAL": "second", "NESTED": "second"}, MapSource{"NESTED": "third"})); err != nil {
+		t.Error(err)
+	}
+
+	if c.Literal != "second" {
+		t.Error("source priority LITERAL")
+	}
+
+	if c.Nested != "second" {
+		t.Error("source priority NESTED")
+	}
+}
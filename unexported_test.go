@@ -0,0 +1,95 @@
+package config
+
+import (
+	"errors"
+	"testing"
+)
+
+type unexportedBase struct {
+	Region string `env:"REGION" default:"eu"`
+	zone   string
+}
+
+type unexportedTLS struct {
+	Cert string `env:"CERT"`
+}
+
+type ConfigUnexported struct {
+	unexportedBase
+	*unexportedTLS
+	Host  string `env:"HOST"`
+	port  int
+	cache struct {
+		Size int `env:"SIZE"`
+	}
+}
+
+type ConfigUnexportedTagged struct {
+	Host string `env:"HOST"`
+	port int    `env:"PORT"`
+}
+
+type Panicky string
+
+func (p *Panicky) UnmarshalENV(data []byte) error {
+	if string(data) == "boom" {
+		panic("boom")
+	}
+
+	*p = Panicky(data)
+	return nil
+}
+
+type ConfigPanic struct {
+	DB struct {
+		Name Panicky `env:"NAME"`
+	} `env:"DB"`
+}
+
+func TestUnexportedFields(t *testing.T) {
+	c := &ConfigUnexported{port: 1}
+	if err := ReadENV(c, WithSources(MapSource{"HOST": "h", "CERT": "c", "SIZE": "1"})); err != nil {
+		t.Error(err)
+	}
+
+	if c.Host != "h" || c.Region != "eu" || c.port != 1 || c.unexportedTLS != nil || c.cache.Size != 0 {
+		t.Error("unexported fields", c)
+	}
+
+	err := ReadENV(&ConfigUnexportedTagged{}, WithSources(MapSource{}))
+
+	var de *DecodeError
+	if !errors.As(err, &de) || de.Field != "port" || de.Var != "PORT" || de.Err.Error() != "unexported field port cannot be set; export it or remove its env tag" {
+		t.Error("unexported tagged", err)
+	}
+}
+
+func TestRecoverPanic(t *testing.T) {
+	err := ReadENV(&ConfigPanic{}, WithSources(MapSource{"DB_NAME": "boom"}))
+
+	var de *DecodeError
+	if !errors.As(err, &de) || de.Field != "DB.Name" || err.Error() != "DB.Name: panic: boom" {
+		t.Error("recover panic", err)
+	}
+
+	if err := ReadENV(ConfigPanic{}, WithSources(MapSource{"DB_NAME": "x"})); err == nil || err.Error() != "ReadENV: non-nil pointer required" {
+		t.Error("recover non-pointer", err)
+	}
+
+	if err := ReadENV(nil); err == nil || err.Error() != "ReadENV: non-nil pointer required" {
+		t.Error("recover nil", err)
+	}
+
+	if err := ReadENV((*ConfigPanic)(nil)); err == nil || err.Error() != "ReadENV: non-nil pointer required" {
+		t.Error("recover nil pointer", err)
+	}
+
+	var c *ConfigUnexported
+	if err := ReadENV(&c, WithSources(MapSource{"HOST": "h"})); err != nil {
+		t.Error("pointer to pointer", err)
+	}
+
+	if c == nil || c.Host != "h" {
+		t.Error("pointer to pointer allocated", c)
+	}
+}
@@ -0,0 +1,121 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+type UnknownError struct {
+	Name       string
+	Suggestion string
+}
+
+func (e *UnknownError) Error() string {
+	if e.Suggestion == "" {
+		return fmt.Sprintf("unknown variable %s", e.Name)
+	}
+
+	return fmt.Sprintf("unknown variable %s, did you mean %s?", e.Name, e.Suggestion)
+}
+
+func (d *decoder) checkUnknown(t reflect.Type, prefix string) {
+	known := map[string]bool{}
+	var names []string
+	for _, f := range fields(t) {
+		known[f.Name] = true
+		known[f.Name+fileSuffix] = true
+		names = append(names, f.Name)
+		for _, a := range f.Aliases {
+			known[a] = true
+			known[a+fileSuffix] = true
+		}
+	}
+
+	seen := map[string]bool{}
+	var unknown []string
+	for _, s := range d.sources {
+		l, ok := s.(Lister)
+		if !ok {
+			continue
+		}
+
+		for _, key := range l.Keys() {
+			if hasPrefix(key, prefix, names) && !known[key] && !seen[key] {
+				seen[key] = true
+				unknown = append(unknown, key)
+			}
+		}
+	}
+	sort.Strings(unknown)
+
+	for _, key := range unknown {
+		d.warning(&UnknownError{Name: key, Suggestion: suggest(key, names)})
+	}
+}
+
+func (d *decoder) warning(err error) {
+	switch {
+	case d.strict:
+		d.errs = append(d.errs, err)
+	case d.warn != nil:
+		d.warn(err)
+	}
+}
+
+// hasPrefix reports whether key starts with prefix. A key whose prefix is
+// one edit away, such as SERVCE_PORT under SERVICE_, also counts when it is
+// a likely typo of a field's name, so unrelated variables are left alone.
+func hasPrefix(key, prefix string, names []string) bool {
+	if strings.HasPrefix(key, prefix) {
+		return true
+	}
+
+	if len(prefix) < 4 {
+		return false
+	}
+
+	for n := len(prefix) - 1; n <= len(prefix)+1 && n <= len(key); n++ {
+		if levenshtein(key[:n], prefix) <= 1 {
+			return suggest(key, names) != ""
+		}
+	}
+
+	return false
+}
+
+// suggest returns the name closest to key, if any is close enough to be a
+// likely typo.
+func suggest(key string, names []string) string {
+	best, bestDist := "", len(key)/4+1
+	for _, name := range names {
+		if dist := levenshtein(key, name); dist < bestDist {
+			best, bestDist = name, dist
+		}
+	}
+
+	return best
+}
+
+func levenshtein(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(b)]
+}
@@ -0,0 +1,96 @@
+package config
+
+import (
+	"errors"
+	"testing"
+)
+
+type ConfigUnknown struct {
+	Port int    `env:"SERVICE_PORT" default:"8080"`
+	Host string `env:"SERVICE_HOST"`
+	Key  string `env:"SERVICE_KEY"`
+}
+
+func TestCheckUnknown(t *testing.T) {
+	src := MapSource{
+		"SERVCE_PORT":      "9090",
+		"SERVICE_KEY_FILE": "",
+		"SERVICE_DEBUG":    "true",
+		"OTHER_PORT":       "1",
+	}
+
+	var warnings []error
+	warn := OnWarning(func(err error) {
+		warnings = append(warnings, err)
+	})
+
+	if err := ReadENV(&ConfigUnknown{}, WithSources(src), CheckUnknown("SERVICE_"), warn); err != nil {
+		t.Error(err)
+	}
+
+	if len(warnings) != 2 || warnings[0].Error() != "unknown variable SERVCE_PORT, did you mean SERVICE_PORT?" || warnings[1].Error() != "unknown variable SERVICE_DEBUG" {
+		t.Error("unknown SERVICE_", warnings)
+	}
+
+	warnings = nil
+
+	if err := ReadENV(&ConfigUnknown{}, WithSources(src), CheckUnknown("SERV"), warn); err != nil {
+		t.Error(err)
+	}
+
+	if len(warnings) != 2 || warnings[0].Error() != "unknown variable SERVCE_PORT, did you mean SERVICE_PORT?" {
+		t.Error("unknown SERV", warnings)
+	}
+
+	err := ReadENV(&ConfigUnknown{}, WithSources(src), CheckUnknown("SERV"), Strict())
+
+	var errs Errors
+	var ue *UnknownError
+	if !errors.As(err, &errs) || len(errs) != 2 || !errors.As(errs[0], &ue) || ue.Name != "SERVCE_PORT" || ue.Suggestion != "SERVICE_PORT" {
+		t.Error("unknown strict", err)
+	}
+}
+
+func TestCheckUnknownPrefixTypo(t *testing.T) {
+	type config struct {
+		Port int    `env:"SERVICE_PORT"`
+		Host string `env:"SERVICE_HOST"`
+		App  struct {
+			Port int    `env:"PORT"`
+			Name string `env:"NAME"`
+		} `env:"APP"`
+	}
+
+	for _, c := range []struct {
+		prefix, key string
+		want        bool
+	}{
+		{"SERVICE_", "SERVCE_PORT", true},
+		{"SERVICE_", "SERVICES_PORT", true},
+		{"SERVICE_", "SERIVCE_PORT", false},
+		{"SERVICE_", "SERVER_PORT", false},
+		{"SERVICE_", "SERVCE_UNRELATED", false},
+		{"SERVICE_", "OTHER_PORT", false},
+		{"APP_", "APP_DEBUG", true},
+		{"APP_", "APPDATA", false},
+		{"APP_", "API_KEY", false},
+		{"APP_", "APPLICATION_ID", false},
+	} {
+		var warnings []error
+		err := ReadENV(&config{}, WithSources(MapSource{c.key: "1", "APP_PORT": "1"}), CheckUnknown(c.prefix), OnWarning(func(err error) {
+			warnings = append(warnings, err)
+		}))
+		if err != nil {
+			t.Error(err)
+		}
+
+		if got := len(warnings) == 1; got != c.want {
+			t.Error("unknown prefix typo", c.prefix, c.key, warnings)
+		}
+	}
+
+	err := ReadENV(&config{}, WithSources(MapSource{"API_KEY": "x", "APPDATA": "x", "APP_PORT": "1"}), CheckUnknown("APP_"), Strict())
+	if err != nil {
+		t.Error("unknown unrelated strict", err)
+	}
+}
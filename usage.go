@@ -0,0 +1,54 @@
+package config
+
+import (
+	"errors"
+	"io"
+	"reflect"
+
+	"github.com/GMTror/config/internal/render"
+)
+
+type Format int
+
+const (
+	FormatText Format = iota
+	FormatMarkdown
+	FormatJSON
+	FormatYAML
+)
+
+// Usage writes every variable read by ReadENV for i along with its type,
+// default and description, as FormatText, FormatMarkdown or FormatJSON.
+// FormatYAML is only supported by Dump.
+func Usage(i interface{}, w io.Writer, format Format) error {
+	entries := usage(reflect.TypeOf(i))
+
+	switch format {
+	case FormatText:
+		return render.Text(w, entries)
+	case FormatMarkdown:
+		return render.Markdown(w, entries)
+	case FormatJSON:
+		return render.JSON(w, entries)
+	}
+
+	return errors.New("unsupported format: Usage supports FormatText, FormatMarkdown and FormatJSON")
+}
+
+func usage(t reflect.Type) []render.Entry {
+	fs := fields(t)
+	entries := make([]render.Entry, 0, len(fs))
+	for _, f := range fs {
+		entries = append(entries, render.Entry{
+			Name:        f.Name,
+			Field:       f.Path,
+			Type:        f.Type.String(),
+			Default:     f.Default,
+			Required:    f.Required,
+			Description: f.Description,
+			Secret:      f.Secret,
+		})
+	}
+
+	return entries
+}
@@ -0,0 +1,95 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/GMTror/config/internal/render"
+)
+
+type ConfigUsage struct {
+	Host     string `env:"HOST" default:"localhost" description:"listen host"`
+	Password string `env:"PASSWORD" default:"hunter2" secret:"true" description:"a | b"`
+	Token    string `env:"TOKEN" required:"true" description:"api token"`
+	DB       struct {
+		Port int `env:"PORT" default:"5432"`
+	} `env:"DB"`
+}
+
+func TestUsage(t *testing.T) {
+	var buf bytes.Buffer
+
+	if err := Usage(&ConfigENV{}, &buf, FormatText); err != nil {
+		t.Error(err)
+	}
+
+	out := buf.String()
+
+	if !strings.HasPrefix(out, "NAME ") {
+		t.Error("usage text header")
+	}
+
+	if !strings.Contains(out, "STRUCT_STRING") || !strings.Contains(out, "this is description") {
+		t.Error("usage text STRUCT_STRING")
+	}
+
+	if strings.Contains(out, "PAS_STRING") {
+		t.Error("usage text STRUCT_PAS_STRING")
+	}
+
+	if n := strings.Count(out, "\n"); n != 47 {
+		t.Error("usage text lines", n)
+	}
+
+	buf.Reset()
+
+	if err := Usage(&ConfigUsage{}, &buf, FormatMarkdown); err != nil {
+		t.Error(err)
+	}
+
+	for _, want := range []string{
+		"| `HOST` | `string` | `localhost` | no | listen host |",
+		"| `PASSWORD` | `string` | `[REDACTED]` | no | a \\| b |",
+		"| `TOKEN` | `string` |  | yes | api token |",
+		"| `DB_PORT` | `int` | `5432` | no |  |",
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Error("usage markdown", want)
+		}
+	}
+
+	buf.Reset()
+
+	if err := Usage(&ConfigUsage{}, &buf, FormatJSON); err != nil {
+		t.Error(err)
+	}
+
+	var entries []render.Entry
+	if err := json.Unmarshal(buf.Bytes(), &entries); err != nil {
+		t.Error(err)
+	}
+
+	if len(entries) != 4 || entries[3].Name != "DB_PORT" || entries[3].Field != "DB.Port" || !entries[2].Required {
+		t.Error("usage json", entries)
+	}
+
+	if err := Usage(&ConfigUsage{}, &buf, FormatYAML); err == nil || !strings.Contains(err.Error(), "FormatJSON") {
+		t.Error("usage yaml", err)
+	}
+}
+
+func TestRequired(t *testing.T) {
+	c := &ConfigUsage{}
+
+	if err := ReadENV(c, WithSources(MapSource{"TOKEN": "token"})); err != nil {
+		t.Error(err)
+	}
+
+	err := ReadENV(c, WithSources(MapSource{}))
+	if !errors.Is(err, ErrRequired) || !strings.Contains(err.Error(), "Token (TOKEN)") {
+		t.Error("required TOKEN", err)
+	}
+}
@@ -0,0 +1,237 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	minName = "min"
+	maxName = "max"
+	lenName = "len"
+	rexName = "regexp"
+	nzrName = "nonzero"
+	fmtName = "format"
+)
+
+// Validator is implemented by config structs that check themselves once
+// their fields are decoded.
+type Validator interface {
+	Validate() error
+}
+
+func (d *decoder) validate(result reflect.Value) {
+	if !result.CanInterface() {
+		return
+	}
+
+	var v Validator
+	if result.CanAddr() {
+		v, _ = result.Addr().Interface().(Validator)
+	} else {
+		v, _ = result.Interface().(Validator)
+	}
+
+	if v == nil {
+		return
+	}
+
+	if err := v.Validate(); err != nil {
+		d.errs = append(d.errs, &DecodeError{Field: strings.Join(d.path, "."), Err: err})
+	}
+}
+
+// check applies the constraint tags of a field to its decoded value.
+func (d *decoder) check(result reflect.Value, f *fieldPlan) {
+	if len(f.checks) == 0 {
+		return
+	}
+
+	for result.Kind() == reflect.Ptr {
+		if result.IsNil() {
+			return
+		}
+		result = result.Elem()
+	}
+
+	for _, c := range f.checks {
+		if err := checkConstraint(result, c); err != nil {
+			d.errs = append(d.errs, d.error(f.tag, err))
+		}
+	}
+}
+
+func checkConstraint(result reflect.Value, c constraint) error {
+	if c.err != nil {
+		return c.err
+	}
+
+	arg := c.arg
+	switch c.name {
+	case nzrName:
+		if arg == "true" && result.IsZero() {
+			return errors.New("must not be zero")
+		}
+	case minName:
+		return checkBound(result, arg, -1)
+	case maxName:
+		return checkBound(result, arg, 1)
+	case lenName:
+		n, err := strconv.Atoi(arg)
+		if err != nil {
+			return fmt.Errorf("invalid len tag %q", arg)
+		}
+
+		l, ok := length(result)
+		if !ok {
+			return fmt.Errorf("len tag not supported for %s", result.Type())
+		}
+
+		if l != n {
+			return fmt.Errorf("length must be %d", n)
+		}
+	case rexName:
+		if result.Kind() != reflect.String {
+			return fmt.Errorf("regexp tag not supported for %s", result.Type())
+		}
+
+		if !c.re.MatchString(result.String()) {
+			return fmt.Errorf("must match %q", arg)
+		}
+	case fmtName:
+		if result.Kind() != reflect.String {
+			return fmt.Errorf("format tag not supported for %s", result.Type())
+		}
+
+		return checkFormat(result.String(), arg)
+	}
+
+	return nil
+}
+
+// checkBound compares numbers by value and strings, slices and maps by
+// length. sign is -1 for a lower bound and 1 for an upper bound.
+func checkBound(result reflect.Value, arg string, sign int) error {
+	var cmp int
+	var err error
+	what := "value"
+
+	switch result.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var b int64
+		if b, err = strconv.ParseInt(arg, 10, 64); err == nil {
+			cmp = compare(result.Int(), b)
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		var b uint64
+		if b, err = strconv.ParseUint(arg, 10, 64); err == nil {
+			cmp = compare(result.Uint(), b)
+		}
+	case reflect.Float32, reflect.Float64:
+		var b float64
+		if b, err = strconv.ParseFloat(arg, 64); err == nil {
+			cmp = compare(result.Float(), b)
+		}
+	default:
+		l, ok := length(result)
+		if !ok {
+			return fmt.Errorf("bound not supported for %s", result.Type())
+		}
+
+		var b int
+		if b, err = strconv.Atoi(arg); err == nil {
+			cmp = compare(l, b)
+		}
+		what = "length"
+	}
+
+	if err != nil {
+		return fmt.Errorf("invalid bound %q", arg)
+	}
+
+	switch {
+	case cmp == sign && sign < 0:
+		return fmt.Errorf("%s must be at least %s", what, arg)
+	case cmp == sign:
+		return fmt.Errorf("%s must be at most %s", what, arg)
+	}
+
+	return nil
+}
+
+func compare[T int | int64 | uint64 | float64](a, b T) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+
+	return 0
+}
+
+func length(result reflect.Value) (int, bool) {
+	switch result.Kind() {
+	case reflect.String:
+		return utf8.RuneCountInString(result.String()), true
+	case reflect.Slice, reflect.Map, reflect.Array:
+		return result.Len(), true
+	}
+
+	return 0, false
+}
+
+func checkFormat(val, format string) error {
+	if val == "" {
+		return nil
+	}
+
+	switch format {
+	case "url":
+		u, err := url.Parse(val)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return errors.New("must be an absolute URL")
+		}
+	case "hostname":
+		if !isHostname(val) {
+			return errors.New("must be a hostname")
+		}
+	case "email":
+		a, err := mail.ParseAddress(val)
+		if err != nil || a.Address != val {
+			return errors.New("must be an email address")
+		}
+	default:
+		return fmt.Errorf("unknown format %q", format)
+	}
+
+	return nil
+}
+
+// isHostname reports whether s is a valid RFC 1123 host name.
+func isHostname(s string) bool {
+	s = strings.TrimSuffix(s, ".")
+	if s == "" || len(s) > 253 {
+		return false
+	}
+
+	for _, label := range strings.Split(s, ".") {
+		if label == "" || len(label) > 63 || label[0] == '-' || label[len(label)-1] == '-' {
+			return false
+		}
+
+		for _, c := range label {
+			if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-') {
+				return false
+			}
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,84 @@
+package config
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+type ConfigValidateNested struct {
+	Name string `env:"NAME" nonzero:"true"`
+	Mode string `env:"MODE" default:"slow"`
+}
+
+func (c *ConfigValidateNested) Validate() error {
+	if c.Mode != "fast" && c.Mode != "slow" {
+		return errors.New("mode must be fast or slow")
+	}
+
+	return nil
+}
+
+type ConfigValidate struct {
+	Port    int                  `env:"PORT" default:"8080" min:"1" max:"65535"`
+	Ratio   float64              `env:"RATIO" default:"0.5" min:"0" max:"1"`
+	Code    string               `env:"CODE" default:"ab" len:"2" regexp:"^[a-z]+$"`
+	Hosts   []string             `env:"HOSTS" default:"a" min:"1" max:"3"`
+	URL     string               `env:"URL" default:"https://example.com" format:"url"`
+	Host    string               `env:"HOST" default:"example.com" format:"hostname"`
+	Email   string               `env:"EMAIL" default:"ops@example.com" format:"email"`
+	Timeout *uint                `env:"TIMEOUT" default:"10" max:"60"`
+	Nested  ConfigValidateNested `env:"NESTED"`
+}
+
+func TestValidate(t *testing.T) {
+	c := &ConfigValidate{}
+
+	if err := ReadENV(c, WithSources(MapSource{"NESTED_NAME": "name"})); err != nil {
+		t.Error(err)
+	}
+
+	err := ReadENV(&ConfigValidate{}, WithSources(MapSource{
+		"PORT":        "0",
+		"RATIO":       "1.5",
+		"CODE":        "ABC",
+		"HOSTS":       "a,b,c,d",
+		"URL":         "example.com",
+		"HOST":        "-example.com",
+		"EMAIL":       "ops",
+		"TIMEOUT":     "61",
+		"NESTED_MODE": "medium",
+	}))
+
+	var errs Errors
+	if !errors.As(err, &errs) {
+		t.Fatal("validate errors", err)
+	}
+
+	for _, want := range []string{
+		"Port (PORT): value must be at least 1",
+		"Ratio (RATIO): value must be at most 1",
+		"Code (CODE): length must be 2",
+		"Code (CODE): must match",
+		"Hosts (HOSTS): length must be at most 3",
+		"URL (URL): must be an absolute URL",
+		"Host (HOST): must be a hostname",
+		"Email (EMAIL): must be an email address",
+		"Timeout (TIMEOUT): value must be at most 60",
+		"Nested.Name (NESTED_NAME): must not be zero",
+		"Nested: mode must be fast or slow",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Error("validate error", want)
+		}
+	}
+
+	if len(errs) != 11 {
+		t.Error("validate errors count", len(errs))
+	}
+
+	var de *DecodeError
+	if !errors.As(errs[0], &de) || de.Field != "Port" || de.Var != "PORT" {
+		t.Error("validate error field", errs[0])
+	}
+}
@@ -0,0 +1,362 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type StaticPolicy int
+
+const (
+	// ApplyStatic applies every change and still lists the static ones in
+	// Event.RestartRequired. It is the default for configs without dynamic
+	// fields.
+	ApplyStatic StaticPolicy = iota
+	// FlagStatic applies changes to dynamic fields only and reports the
+	// others in Event.RestartRequired. It is the default for configs that
+	// tag any field dynamic:"true".
+	FlagStatic
+	// RejectStatic rejects a reload that changes any static field.
+	RejectStatic
+)
+
+// Event describes a reload. On failure Err is set, New is nil and the
+// Watcher keeps serving Old.
+type Event[T any] struct {
+	Old             *T
+	New             *T
+	Changed         []string
+	RestartRequired []string
+	Err             error
+}
+
+type RestartRequiredError struct {
+	Paths []string
+}
+
+func (e *RestartRequiredError) Error() string {
+	return "restart required to change " + strings.Join(e.Paths, ", ")
+}
+
+type pathSub struct {
+	path string
+	fn   func(old, new any)
+}
+
+// Watcher holds a config of type T and replaces it atomically whenever a
+// reload produces a different value.
+type Watcher[T any] struct {
+	opts    []Option
+	options *options
+	static  StaticPolicy
+	current atomic.Pointer[T]
+
+	reload sync.Mutex
+	mu     sync.Mutex
+	subs   []func(Event[T])
+	paths  []pathSub
+	stamps map[string]string
+
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+func NewWatcher[T any](opts ...Option) (*Watcher[T], error) {
+	w := &Watcher[T]{opts: opts, options: newOptions(opts), done: make(chan struct{})}
+
+	c := new(T)
+	if err := ReadENV(c, opts...); err != nil {
+		return nil, err
+	}
+	w.current.Store(c)
+	w.static = staticPolicy(w.options.static, reflect.TypeOf(c))
+
+	for _, src := range w.options.sources {
+		if p, ok := src.(interface{ Path() string }); ok {
+			w.options.watchFiles = append(w.options.watchFiles, p.Path())
+		}
+	}
+
+	if len(w.options.watchFiles) > 0 {
+		w.stamps = stamps(w.options.watchFiles)
+		w.wg.Add(1)
+		go w.poll()
+	}
+
+	if len(w.options.signals) > 0 {
+		ch := make(chan os.Signal, 1)
+		signal.Notify(ch, w.options.signals...)
+		w.wg.Add(1)
+		go w.notify(ch)
+	}
+
+	return w, nil
+}
+
+func (w *Watcher[T]) Load() *T {
+	return w.current.Load()
+}
+
+// Subscribe registers fn to be called after every reload that changes the
+// config or fails.
+func (w *Watcher[T]) Subscribe(fn func(Event[T])) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.subs = append(w.subs, fn)
+}
+
+// OnChange registers fn to be called with the old and new value of the field
+// at path, such as "DB.Port", whenever a reload changes it or any field
+// below it.
+func (w *Watcher[T]) OnChange(path string, fn func(old, new any)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.paths = append(w.paths, pathSub{path: path, fn: fn})
+}
+
+// Reload decodes the config again and swaps it in if it changed.
+// Subscribers are called after the swap, so they may call Reload, Subscribe
+// or OnChange themselves.
+func (w *Watcher[T]) Reload() error {
+	e, err := w.swap()
+	if e != nil {
+		w.publish(*e)
+	}
+
+	return err
+}
+
+// swap decodes the config and stores it if it changed. It returns the event
+// to publish, if any.
+func (w *Watcher[T]) swap() (*Event[T], error) {
+	w.reload.Lock()
+	defer w.reload.Unlock()
+
+	old := w.current.Load()
+	c := new(T)
+	if err := w.refresh(); err != nil {
+		return &Event[T]{Old: old, Err: err}, err
+	}
+
+	if err := ReadENV(c, w.opts...); err != nil {
+		return &Event[T]{Old: old, Err: err}, err
+	}
+
+	var changed, static []string
+	for _, f := range diff(old, c) {
+		if !f.Dynamic {
+			static = append(static, f.Path)
+		}
+
+		if f.Dynamic || w.static == ApplyStatic {
+			changed = append(changed, f.Path)
+		}
+	}
+
+	if len(static) > 0 && w.static != ApplyStatic && (w.static == RejectStatic || !revert(c, old, static)) {
+		err := &RestartRequiredError{Paths: static}
+		return &Event[T]{Old: old, RestartRequired: static, Err: err}, err
+	}
+
+	if len(changed) == 0 && len(static) == 0 {
+		return nil, nil
+	}
+
+	e := &Event[T]{Old: old, New: old, Changed: changed, RestartRequired: static}
+	if len(changed) > 0 {
+		w.current.Store(c)
+		e.New = c
+	}
+
+	return e, nil
+}
+
+// staticPolicy returns p if it was set, or the default for t.
+func staticPolicy(p *StaticPolicy, t reflect.Type) StaticPolicy {
+	if p != nil {
+		return *p
+	}
+
+	for _, f := range fields(t) {
+		if f.Dynamic {
+			return FlagStatic
+		}
+	}
+
+	return ApplyStatic
+}
+
+// refresh rereads sources backed by files, such as FileSource.
+func (w *Watcher[T]) refresh() error {
+	for _, src := range w.options.sources {
+		if r, ok := src.(interface{ Refresh() error }); ok {
+			if err := r.Refresh(); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (w *Watcher[T]) Close() {
+	select {
+	case <-w.done:
+	default:
+		close(w.done)
+	}
+	w.wg.Wait()
+}
+
+func (w *Watcher[T]) publish(e Event[T]) {
+	w.mu.Lock()
+	subs := append([]func(Event[T]){}, w.subs...)
+	paths := append([]pathSub{}, w.paths...)
+	w.mu.Unlock()
+
+	for _, fn := range subs {
+		fn(e)
+	}
+
+	if e.Err != nil || e.New == e.Old {
+		return
+	}
+
+	for _, sub := range paths {
+		for _, path := range e.Changed {
+			if path == sub.path || strings.HasPrefix(path, sub.path+".") {
+				sub.fn(valueAt(e.Old, sub.path), valueAt(e.New, sub.path))
+				break
+			}
+		}
+	}
+}
+
+func (w *Watcher[T]) poll() {
+	defer w.wg.Done()
+
+	t := time.NewTicker(w.options.pollInterval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-w.done:
+			return
+		case <-t.C:
+			next := stamps(w.options.watchFiles)
+			if !reflect.DeepEqual(next, w.stamps) {
+				w.stamps = next
+				w.Reload()
+			}
+		}
+	}
+}
+
+func (w *Watcher[T]) notify(ch chan os.Signal) {
+	defer w.wg.Done()
+	defer signal.Stop(ch)
+
+	for {
+		select {
+		case <-w.done:
+			return
+		case <-ch:
+			w.Reload()
+		}
+	}
+}
+
+// stamps fingerprints files by size and modification time. Missing files
+// get an empty stamp so that their creation is noticed.
+func stamps(paths []string) map[string]string {
+	m := make(map[string]string, len(paths))
+	for _, path := range paths {
+		m[path] = stamp(path)
+	}
+
+	return m
+}
+
+// stamp fingerprints a file, or a directory by its files and the target of
+// its ..data link, so that DirSource updates and symlink swaps are seen.
+func stamp(path string) string {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return ""
+	}
+
+	s := fmt.Sprintf("%d/%d", fi.Size(), fi.ModTime().UnixNano())
+	if !fi.IsDir() {
+		return s
+	}
+
+	if target, err := os.Readlink(filepath.Join(path, dataLink)); err == nil {
+		s += " " + target
+	}
+
+	entries, _ := os.ReadDir(path)
+	for _, e := range entries {
+		if strings.HasPrefix(e.Name(), ".") {
+			continue
+		}
+
+		if fi, err := os.Stat(filepath.Join(path, e.Name())); err == nil {
+			s += fmt.Sprintf(" %s:%d/%d", e.Name(), fi.Size(), fi.ModTime().UnixNano())
+		}
+	}
+
+	return s
+}
+
+// diff returns the fields that differ between a and b.
+func diff(a, b interface{}) []field {
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+
+	var changed []field
+	for _, f := range fields(va.Type()) {
+		fa, oka := fieldByIndex(va, f.Index)
+		fb, okb := fieldByIndex(vb, f.Index)
+		if oka != okb || oka && !reflect.DeepEqual(fa.Interface(), fb.Interface()) {
+			changed = append(changed, f)
+		}
+	}
+
+	return changed
+}
+
+// revert copies the fields at paths from old into c. It reports false if a
+// path cannot be reached in both.
+func revert(c, old interface{}, paths []string) bool {
+	for _, path := range paths {
+		dst, ok := fieldByPath(reflect.ValueOf(c), path)
+		if !ok {
+			return false
+		}
+
+		src, ok := fieldByPath(reflect.ValueOf(old), path)
+		if !ok {
+			return false
+		}
+
+		dst.Set(src)
+	}
+
+	return true
+}
+
+func valueAt(c interface{}, path string) any {
+	if v, ok := fieldByPath(reflect.ValueOf(c), path); ok {
+		return v.Interface()
+	}
+
+	return nil
+}
@@ -0,0 +1,229 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type ConfigWatch struct {
+	Level    string `env:"LEVEL" default:"info"`
+	Port     int    `env:"PORT" default:"8080"`
+	Password string `env:"PASSWORD"`
+}
+
+func TestWatcher(t *testing.T) {
+	password := filepath.Join(t.TempDir(), "password")
+	if err := os.WriteFile(password, []byte("one"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	src := MapSource{"PASSWORD_FILE": password}
+	w, err := NewWatcher[ConfigWatch](WithSources(src), WatchFiles(password), PollInterval(10*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	events := make(chan Event[ConfigWatch], 10)
+	w.Subscribe(func(e Event[ConfigWatch]) {
+		events <- e
+	})
+
+	first := w.Load()
+	if first.Password != "one" || first.Level != "info" {
+		t.Error("watcher initial load")
+	}
+
+	if err := os.WriteFile(password, []byte("two!"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case e := <-events:
+		if e.Err != nil || e.Old != first || e.New.Password != "two!" || len(e.Changed) != 1 || e.Changed[0] != "Password" {
+			t.Error("watcher file event", e)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("watcher file event timeout")
+	}
+
+	if w.Load().Password != "two!" {
+		t.Error("watcher swap")
+	}
+
+	if err := w.Reload(); err != nil {
+		t.Error(err)
+	}
+
+	if len(events) != 0 {
+		t.Error("watcher event without change")
+	}
+
+	src["LEVEL"] = "debug"
+	src["PORT"] = "9090"
+
+	if err := w.Reload(); err != nil {
+		t.Error(err)
+	}
+
+	if e := <-events; len(e.Changed) != 2 || e.Changed[0] != "Level" || e.Changed[1] != "Port" || len(e.RestartRequired) != 2 {
+		t.Error("watcher reload event", e.Changed, e.RestartRequired)
+	}
+
+	current := w.Load()
+	src["PORT"] = "port"
+
+	if err := w.Reload(); err == nil {
+		t.Error("watcher reload error")
+	}
+
+	if e := <-events; e.Err == nil || e.Old != current || e.New != nil {
+		t.Error("watcher error event", e)
+	}
+
+	if w.Load() != current {
+		t.Error("watcher kept config")
+	}
+}
+
+type ConfigDynamic struct {
+	Listen string `env:"LISTEN" default:":8080"`
+	Level  string `env:"LEVEL" default:"info" dynamic:"true"`
+	Limits struct {
+		Rate  int `env:"RATE" default:"10"`
+		Burst int `env:"BURST" default:"20"`
+	} `env:"LIMITS" dynamic:"true"`
+}
+
+func TestWatcherDynamic(t *testing.T) {
+	src := MapSource{}
+	w, err := NewWatcher[ConfigDynamic](WithSources(src), StaticChanges(FlagStatic))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	var events []Event[ConfigDynamic]
+	w.Subscribe(func(e Event[ConfigDynamic]) {
+		events = append(events, e)
+	})
+
+	var level, limits [][2]any
+	w.OnChange("Level", func(old, new any) {
+		level = append(level, [2]any{old, new})
+	})
+	w.OnChange("Limits", func(old, new any) {
+		limits = append(limits, [2]any{old, new})
+	})
+
+	src["LEVEL"] = "debug"
+	src["LIMITS_RATE"] = "5"
+	src["LISTEN"] = ":9090"
+
+	if err := w.Reload(); err != nil {
+		t.Error(err)
+	}
+
+	c := w.Load()
+	if c.Level != "debug" || c.Limits.Rate != 5 || c.Listen != ":8080" {
+		t.Error("dynamic reload", c)
+	}
+
+	if len(events) != 1 || len(events[0].Changed) != 2 || len(events[0].RestartRequired) != 1 || events[0].RestartRequired[0] != "Listen" {
+		t.Error("dynamic event", events)
+	}
+
+	if len(level) != 1 || level[0][0] != "info" || level[0][1] != "debug" {
+		t.Error("dynamic OnChange Level", level)
+	}
+
+	if len(limits) != 1 {
+		t.Error("dynamic OnChange Limits", limits)
+	}
+
+	w, err = NewWatcher[ConfigDynamic](WithSources(src), StaticChanges(RejectStatic))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	c = w.Load()
+	src["LEVEL"] = "warn"
+	src["LISTEN"] = ":7070"
+
+	var re *RestartRequiredError
+	if err := w.Reload(); !errors.As(err, &re) || len(re.Paths) != 1 || re.Paths[0] != "Listen" {
+		t.Error("dynamic reject", err)
+	}
+
+	if w.Load() != c {
+		t.Error("dynamic reject kept config")
+	}
+}
+
+func TestWatcherReentrant(t *testing.T) {
+	src := MapSource{}
+	w, err := NewWatcher[ConfigWatch](WithSources(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	var calls int
+	w.Subscribe(func(e Event[ConfigWatch]) {
+		calls++
+		w.Subscribe(func(Event[ConfigWatch]) {})
+		w.OnChange("Port", func(old, new any) {})
+		w.Reload()
+	})
+
+	src["LEVEL"] = "debug"
+
+	done := make(chan struct{})
+	go func() {
+		w.Reload()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("reentrant reload deadlocked")
+	}
+
+	if calls != 1 || w.Load().Level != "debug" {
+		t.Error("reentrant reload", calls)
+	}
+}
+
+func TestWatcherDefaultPolicy(t *testing.T) {
+	src := MapSource{}
+	w, err := NewWatcher[ConfigDynamic](WithSources(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	src["LEVEL"] = "debug"
+	src["LISTEN"] = ":9090"
+
+	var events []Event[ConfigDynamic]
+	w.Subscribe(func(e Event[ConfigDynamic]) {
+		events = append(events, e)
+	})
+
+	if err := w.Reload(); err != nil {
+		t.Error(err)
+	}
+
+	if c := w.Load(); c.Level != "debug" || c.Listen != ":8080" {
+		t.Error("default policy applied static change", c)
+	}
+
+	if len(events) != 1 || len(events[0].RestartRequired) != 1 || events[0].RestartRequired[0] != "Listen" {
+		t.Error("default policy event", events)
+	}
+}